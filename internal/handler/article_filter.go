@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"SmartBook/internal/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parseArticleFilter は記事一覧・検索・おすすめで共通のクエリパラメータを読み込みます
+// source と tag は複数指定 (?tag=go&tag=rust) またはカンマ区切り (?tag=go,rust) で指定できます
+func parseArticleFilter(c echo.Context) (model.ArticleFilter, error) {
+	params := c.QueryParams()
+	filter := model.ArticleFilter{
+		Sources: splitQueryValues(params["source"]),
+		Tags:    splitQueryValues(params["tag"]),
+		Cursor:  c.QueryParam("cursor"),
+	}
+
+	if value := c.QueryParam("since"); value != "" {
+		since, err := parseFilterTime(value)
+		if err != nil {
+			return model.ArticleFilter{}, fmt.Errorf("invalid since: %s", value)
+		}
+		filter.Since = &since
+	}
+	if value := c.QueryParam("until"); value != "" {
+		until, err := parseFilterTime(value)
+		if err != nil {
+			return model.ArticleFilter{}, fmt.Errorf("invalid until: %s", value)
+		}
+		filter.Until = &until
+	}
+	if value := c.QueryParam("min_score"); value != "" {
+		minScore, err := strconv.Atoi(value)
+		if err != nil {
+			return model.ArticleFilter{}, fmt.Errorf("invalid min_score: %s", value)
+		}
+		filter.MinScore = &minScore
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return model.ArticleFilter{}, fmt.Errorf("invalid limit: %s", value)
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func splitQueryValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// parseFilterTime は RFC3339 形式または日付のみ (2006-01-02) の日時を読み込みます
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
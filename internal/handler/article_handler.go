@@ -1,28 +1,41 @@
 package handler
 
 import (
-	"SmartBook/internal/model"
+	"SmartBook/internal/search"
 	"SmartBook/internal/usecase"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
 
 type ArticleHandler struct {
 	articleUseCase *usecase.ArticleUseCase
+	contentUseCase *usecase.ContentUseCase
+	userUseCase    *usecase.UserUseCase
 }
 
-// func NewArticleHandler(articleUseCase *usecase.ArticleUseCase, userUseCase *usecase.UserUseCase) *ArticleHandler {
-func NewArticleHandler(articleUseCase *usecase.ArticleUseCase) *ArticleHandler {
+func NewArticleHandler(articleUseCase *usecase.ArticleUseCase, contentUseCase *usecase.ContentUseCase, userUseCase *usecase.UserUseCase) *ArticleHandler {
 	return &ArticleHandler{
 		articleUseCase: articleUseCase,
+		contentUseCase: contentUseCase,
+		userUseCase:    userUseCase,
 	}
 }
 
 func (h *ArticleHandler) GetLatestArticles(c echo.Context) error {
 	ctx := c.Request().Context()
-	articles, err := h.articleUseCase.GetLatestArticles(ctx)
+	filter, err := parseArticleFilter(c)
 	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	articles, err := h.articleUseCase.GetLatestArticles(ctx, filter)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch latest articles"})
 	}
 	return c.JSON(http.StatusOK, articles)
@@ -38,32 +51,77 @@ func (h *ArticleHandler) GetArticle(c echo.Context) error {
 	return c.JSON(http.StatusOK, article)
 }
 
+func (h *ArticleHandler) GetArticleContent(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("articleId")
+	content, err := h.contentUseCase.GetArticleContent(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrArticleNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Article not found"})
+		case errors.Is(err, usecase.ErrNoArticleURL), errors.Is(err, usecase.ErrUnknownSource), errors.Is(err, usecase.ErrForbiddenURL):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Article has no content to extract"})
+		default:
+			return c.JSON(http.StatusBadGateway, map[string]string{"error": "Failed to extract article content"})
+		}
+	}
+	return c.JSON(http.StatusOK, content)
+}
+
+// GetRelatedArticles はこの記事を保存 (メモ・いいね) したユーザーが他に保存した記事を返します
+func (h *ArticleHandler) GetRelatedArticles(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("articleId")
+
+	limit := 0
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		limit = n
+	}
+
+	articles, err := h.articleUseCase.GetRelatedArticles(ctx, id, limit)
+	if err != nil {
+		if errors.Is(err, usecase.ErrArticleNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Article not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch related articles"})
+	}
+	return c.JSON(http.StatusOK, articles)
+}
+
 func (h *ArticleHandler) GetRecommendedArticles(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	// 認証されたユーザーのIDを取得
-	// 注意: この部分は実際の認証システムに合わせて実装する必要があります
-	// userID := c.Get("user_id").(string) // 例: JWTミドルウェアでセットされたユーザーID
+	// SessionMiddleware でセットされたユーザーIDを取得
+	userID := c.Get("userID").(string)
 
 	// データベースからユーザー情報を取得
-	// user, err := h.userUseCase.GetUserByID(ctx, userID)
-	// if err != nil {
-	// 	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch user information"})
-	// }
-	user := &model.User{
-		ID:          "1",
-		Interests:   []string{"Network", "Go"},
-		Likes:       []string{"Go"},
-		RecentViews: []string{"1", "2"},
+	user, err := h.userUseCase.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch user information"})
 	}
 
-	// ユーザーの興味が設定されていない場合のエラーハンドリング
-	if len(user.Interests) == 0 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User interests are not set"})
+	// 新規登録したユーザーは、興味を選ぶオンボーディングを完了するまで推薦を利用できない
+	if user.NeedsOnboarding() {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{
+			"error": "Onboarding is not completed. Choose your interests with PATCH /api/users/me to get recommendations",
+			"code":  "onboarding_required",
+		})
+	}
+
+	filter, err := parseArticleFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
 	// 推奨記事を取得
-	articles, err := h.articleUseCase.GetRecommendedArticles(ctx, user)
+	articles, err := h.articleUseCase.GetRecommendedArticles(ctx, user, filter)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch recommended articles"})
 	}
@@ -88,14 +146,34 @@ func (h *ArticleHandler) SearchArticles(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Search query is required"})
 	}
 
-	articles, err := h.articleUseCase.SearchArticles(ctx, query)
+	filter, err := parseArticleFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search articles"})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	if len(articles) == 0 {
-		return c.JSON(http.StatusOK, map[string]string{"message": "No articles found matching the query"})
+	response, err := h.articleUseCase.SearchArticles(ctx, query, filter)
+	if err != nil {
+		var parseErr *search.ParseError
+		if errors.As(err, &parseErr) {
+			return invalidSearchQuery(c, parseErr)
+		}
+		if errors.Is(err, usecase.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search articles"})
 	}
 
-	return c.JSON(http.StatusOK, articles)
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ArticleHandler) GetSources(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.articleUseCase.GetSources())
+}
+
+// invalidSearchQuery は検索クエリの構文エラーを、エラーの位置とともに 400 で返します
+func invalidSearchQuery(c echo.Context, parseErr *search.ParseError) error {
+	return c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"error":    "Invalid search query: " + parseErr.Message,
+		"position": parseErr.Pos,
+	})
 }
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"SmartBook/internal/usecase"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InteractionHandler struct {
+	interactionUseCase *usecase.InteractionUseCase
+}
+
+func NewInteractionHandler(interactionUseCase *usecase.InteractionUseCase) *InteractionHandler {
+	return &InteractionHandler{
+		interactionUseCase: interactionUseCase,
+	}
+}
+
+// RecordView は記事の閲覧を記録します。body の dwell_seconds (閲覧した秒数) は省略できます
+func (h *InteractionHandler) RecordView(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	articleID := c.Param("articleId")
+
+	var req struct {
+		DwellSeconds *int `json:"dwell_seconds"`
+	}
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	if err := h.interactionUseCase.RecordView(c.Request().Context(), userID, articleID, req.DwellSeconds); err != nil {
+		return interactionError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *InteractionHandler) LikeArticle(c echo.Context) error {
+	return h.setLike(c, true)
+}
+
+func (h *InteractionHandler) UnlikeArticle(c echo.Context) error {
+	return h.setLike(c, false)
+}
+
+func (h *InteractionHandler) setLike(c echo.Context, liked bool) error {
+	userID := c.Get("userID").(string)
+	articleID := c.Param("articleId")
+
+	if err := h.interactionUseCase.SetLike(c.Request().Context(), userID, articleID, liked); err != nil {
+		return interactionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]bool{"liked": liked})
+}
+
+func interactionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrArticleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Article not found"})
+	case errors.Is(err, usecase.ErrInvalidDwellTime):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to record interaction"})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"SmartBook/internal/model"
 	"SmartBook/internal/usecase"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -141,3 +142,29 @@ func (h *MemoHandler) DeleteMemoHandler(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// SearchMemosHandler は自分のメモを全文検索します
+func (h *MemoHandler) SearchMemosHandler(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	query := c.QueryParam("q")
+
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "q is required"})
+	}
+
+	limit := 30
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		limit = min(n, 100)
+	}
+
+	memos, err := h.memoUseCase.SearchMemos(userID, query, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, memos)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/search"
+	"SmartBook/internal/usecase"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SavedSearchHandler struct {
+	savedSearchUseCase *usecase.SavedSearchUseCase
+}
+
+func NewSavedSearchHandler(savedSearchUseCase *usecase.SavedSearchUseCase) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchUseCase: savedSearchUseCase,
+	}
+}
+
+func (h *SavedSearchHandler) GetSavedSearches(c echo.Context) error {
+	userID := c.Get("userID").(string)
+
+	savedSearches, err := h.savedSearchUseCase.GetSavedSearches(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get saved searches"})
+	}
+
+	return c.JSON(http.StatusOK, savedSearches)
+}
+
+func (h *SavedSearchHandler) GetSavedSearch(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid saved search id"})
+	}
+
+	savedSearch, err := h.savedSearchUseCase.GetSavedSearch(c.Request().Context(), userID, id)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, savedSearch)
+}
+
+func (h *SavedSearchHandler) CreateSavedSearch(c echo.Context) error {
+	userID := c.Get("userID").(string)
+
+	var req model.SavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	savedSearch, err := h.savedSearchUseCase.CreateSavedSearch(c.Request().Context(), userID, req)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, savedSearch)
+}
+
+func (h *SavedSearchHandler) UpdateSavedSearch(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid saved search id"})
+	}
+
+	var req model.SavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	savedSearch, err := h.savedSearchUseCase.UpdateSavedSearch(c.Request().Context(), userID, id, req)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, savedSearch)
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid saved search id"})
+	}
+
+	if err := h.savedSearchUseCase.DeleteSavedSearch(c.Request().Context(), userID, id); err != nil {
+		return savedSearchError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetMatches は検索条件に一致した新しい記事を返し、既読にします
+// all=true を指定した場合は既読の記事も含めて返し、既読にはしません
+func (h *SavedSearchHandler) GetMatches(c echo.Context) error {
+	userID := c.Get("userID").(string)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid saved search id"})
+	}
+
+	all := false
+	if value := c.QueryParam("all"); value != "" {
+		all, err = strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "all must be true or false"})
+		}
+	}
+
+	matches, err := h.savedSearchUseCase.GetMatches(c.Request().Context(), userID, id, all)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, matches)
+}
+
+func savedSearchError(c echo.Context, err error) error {
+	var parseErr *search.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		return invalidSearchQuery(c, parseErr)
+	case errors.Is(err, usecase.ErrSavedSearchNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Saved search not found"})
+	case errors.Is(err, usecase.ErrInvalidSavedSearch), errors.Is(err, usecase.ErrTooManySavedSearches):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process saved search"})
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/usecase"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type UserHandler struct {
+	userUseCase *usecase.UserUseCase
+}
+
+func NewUserHandler(userUseCase *usecase.UserUseCase) *UserHandler {
+	return &UserHandler{
+		userUseCase: userUseCase,
+	}
+}
+
+// GetMe はログイン中のユーザーのプロフィールを返します
+func (h *UserHandler) GetMe(c echo.Context) error {
+	userID := c.Get("userID").(string)
+
+	profile, err := h.userUseCase.GetProfile(c.Request().Context(), userID)
+	if err != nil {
+		return userError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// UpdateMe はログイン中のユーザーの名前・メールアドレス・興味を更新します。省略した項目は変更しません
+func (h *UserHandler) UpdateMe(c echo.Context) error {
+	userID := c.Get("userID").(string)
+
+	var req model.UserProfileUpdate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	profile, err := h.userUseCase.UpdateProfile(c.Request().Context(), userID, req)
+	if err != nil {
+		return userError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// GetInterestVocabulary は興味として選べるタグの候補を返します。q で前方一致の絞り込みができます
+func (h *UserHandler) GetInterestVocabulary(c echo.Context) error {
+	limit := 0
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		limit = n
+	}
+
+	tags, err := h.userUseCase.GetInterestVocabulary(c.Request().Context(), c.QueryParam("q"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch interests"})
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
+
+func userError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	case errors.Is(err, usecase.ErrEmailAlreadyExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidName),
+		errors.Is(err, usecase.ErrInvalidEmail),
+		errors.Is(err, usecase.ErrInvalidInterests):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user"})
+	}
+}
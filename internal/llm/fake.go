@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// fakeArticleIDPattern は推薦のプロンプトに含まれる記事の行 (ID: xxx, Title: ...) から ID を取り出します
+var fakeArticleIDPattern = regexp.MustCompile(`(?m)^ID: ([^,\s]+),`)
+
+// FakeProvider は外部に通信せず、同じリクエストに常に同じ結果を返すプロバイダーです
+// テストやオフラインでの開発に使います
+type FakeProvider struct {
+	// Respond はリクエストに対する応答を返します
+	// nil の場合は推薦の応答の形 ({"recommendations":[{"id", "reason", "confidence"}]}) で、プロンプト中の記事をその順に返します
+	Respond func(req Request) (string, error)
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	if p.Respond != nil {
+		text, err := p.Respond(req)
+		if err != nil {
+			return Response{}, err
+		}
+		return Response{Text: text, Model: "fake"}, nil
+	}
+
+	type recommendation struct {
+		ID         string  `json:"id"`
+		Reason     string  `json:"reason"`
+		Confidence float64 `json:"confidence"`
+	}
+	matches := fakeArticleIDPattern.FindAllStringSubmatch(req.Prompt, -1)
+	recommendations := make([]recommendation, 0, len(matches))
+	for i, match := range matches {
+		recommendations = append(recommendations, recommendation{
+			ID:         match[1],
+			Reason:     fmt.Sprintf("Listed at position %d", i+1),
+			Confidence: float64(len(matches)-i) / float64(len(matches)),
+		})
+	}
+	text, err := json.Marshal(map[string]interface{}{"recommendations": recommendations})
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Text: string(text), Model: "fake"}, nil
+}
+
+// Requests はこれまでに受け取ったリクエストを返します
+func (p *FakeProvider) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// defaultGeminiModel は JSON Schema による応答の指定に対応したモデルです
+const defaultGeminiModel = "gemini-1.5-flash"
+
+// GeminiProvider は Gemini API でテキストを生成します
+type GeminiProvider struct {
+	client *genai.Client
+	model  string
+}
+
+func NewGeminiProvider(ctx context.Context, apiKey string, model string) (*GeminiProvider, error) {
+	if apiKey == "" {
+		return nil, errors.New("GEMINI_API_KEY is not set")
+	}
+
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	return &GeminiProvider{client: client, model: model}, nil
+}
+
+func (p *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	// 設定はリクエストごとに異なるため、モデルは毎回作成する
+	model := p.client.GenerativeModel(p.model)
+	if req.System != "" {
+		model.SystemInstruction = genai.NewUserContent(genai.Text(req.System))
+	}
+	if req.Temperature != nil {
+		model.SetTemperature(*req.Temperature)
+	}
+	if req.MaxTokens > 0 {
+		model.SetMaxOutputTokens(int32(req.MaxTokens))
+	}
+	if req.ResponseSchema != nil {
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = toGeminiSchema(req.ResponseSchema)
+	}
+
+	response, err := model.GenerateContent(ctx, genai.Text(req.Prompt))
+	if err != nil {
+		return Response{}, fmt.Errorf("gemini: %w", err)
+	}
+	if len(response.Candidates) == 0 || response.Candidates[0].Content == nil {
+		return Response{}, errors.New("gemini: no content generated")
+	}
+
+	var sb strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	return Response{Text: sb.String(), Model: p.model}, nil
+}
+
+// toGeminiSchema は Schema を Gemini API のスキーマに変換します
+func toGeminiSchema(schema *Schema) *genai.Schema {
+	if schema == nil {
+		return nil
+	}
+
+	converted := &genai.Schema{
+		Description: schema.Description,
+		Items:       toGeminiSchema(schema.Items),
+		Required:    schema.Required,
+	}
+	switch schema.Type {
+	case "object":
+		converted.Type = genai.TypeObject
+	case "array":
+		converted.Type = genai.TypeArray
+	case "string":
+		converted.Type = genai.TypeString
+	case "number":
+		converted.Type = genai.TypeNumber
+	case "integer":
+		converted.Type = genai.TypeInteger
+	case "boolean":
+		converted.Type = genai.TypeBoolean
+	}
+	if len(schema.Properties) > 0 {
+		converted.Properties = make(map[string]*genai.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			converted.Properties[name] = toGeminiSchema(property)
+		}
+	}
+	return converted
+}
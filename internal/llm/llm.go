@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotConfigured は LLM を使わない設定の場合のエラーです
+var ErrNotConfigured = errors.New("llm provider is not configured")
+
+// Request は LLM に送るリクエストです
+type Request struct {
+	// System はシステムプロンプトです。省略できます
+	System string
+	Prompt string
+	// Temperature は生成のランダムさです。nil の場合はプロバイダーの既定値を使います
+	Temperature *float32
+	// MaxTokens は生成する最大トークン数です。0 の場合はプロバイダーの既定値を使います
+	MaxTokens int
+	// ResponseSchema を指定した場合は、このスキーマに従う JSON で応答させます
+	ResponseSchema *Schema
+}
+
+// Schema は応答の JSON の形を表す JSON Schema のサブセットです
+type Schema struct {
+	// Type は object / array / string / number / integer / boolean のいずれかです
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+}
+
+// Response は LLM が生成した結果です
+type Response struct {
+	Text  string
+	Model string
+}
+
+// LLMProvider はテキストを生成する LLM の実装です
+type LLMProvider interface {
+	// Name はログに表示するプロバイダーの名前です
+	Name() string
+	Generate(ctx context.Context, req Request) (Response, error)
+}
+
+// NewProviderFromEnv は環境変数 LLM_PROVIDER に従ってプロバイダーを作成します
+// gemini / openai / fake / none のいずれかで、未設定の場合は GEMINI_API_KEY があれば gemini、なければ none です
+// none の場合は ErrNotConfigured を返します
+func NewProviderFromEnv(ctx context.Context) (LLMProvider, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER")))
+	if provider == "" {
+		provider = "none"
+		if os.Getenv("GEMINI_API_KEY") != "" {
+			provider = "gemini"
+		}
+	}
+
+	// 作成に失敗した場合に nil のポインタを含むインターフェースを返さないよう、エラーを先に確認する
+	switch provider {
+	case "gemini":
+		gemini, err := NewGeminiProvider(ctx, os.Getenv("GEMINI_API_KEY"), envOrDefault("GEMINI_MODEL", defaultGeminiModel))
+		if err != nil {
+			return nil, err
+		}
+		return gemini, nil
+	case "openai":
+		client := &http.Client{Timeout: durationFromEnv("LLM_TIMEOUT", defaultTimeout)}
+		openai, err := NewOpenAIProvider(client, envOrDefault("OPENAI_BASE_URL", defaultOpenAIBaseURL), os.Getenv("OPENAI_API_KEY"), envOrDefault("OPENAI_MODEL", defaultOpenAIModel))
+		if err != nil {
+			return nil, err
+		}
+		return openai, nil
+	case "fake":
+		return NewFakeProvider(), nil
+	case "none":
+		return nil, ErrNotConfigured
+	}
+	return nil, fmt.Errorf("unknown LLM_PROVIDER %q", provider)
+}
+
+// defaultTimeout は LLM の応答を待つ時間の既定値です
+const defaultTimeout = 60 * time.Second
+
+func envOrDefault(key, def string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return def
+}
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		fmt.Printf("🟡 invalid duration %s=%q, using %s\n", key, value, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAIProvider は OpenAI 互換の Chat Completions API でテキストを生成します
+// llama.cpp や Ollama などのローカルサーバーも baseURL を変えて利用できます
+type OpenAIProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+// NewOpenAIProvider は OpenAIProvider を作成します。ローカルサーバーの場合は apiKey を空にできます
+func NewOpenAIProvider(client *http.Client, baseURL string, apiKey string, model string) (*OpenAIProvider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	if baseURL == "" {
+		return nil, errors.New("OPENAI_BASE_URL is empty")
+	}
+
+	return &OpenAIProvider{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+	}, nil
+}
+
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    *float32        `json:"temperature,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+}
+
+type chatCompletionResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	messages := make([]chatMessage, 0, 2)
+	if req.System != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: req.Prompt})
+
+	completionRequest := chatCompletionRequest{
+		Model:       p.model,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}
+	if req.ResponseSchema != nil {
+		completionRequest.ResponseFormat = &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &jsonSchema{Name: "response", Schema: req.ResponseSchema},
+		}
+	}
+
+	body, err := json.Marshal(completionRequest)
+	if err != nil {
+		return Response{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("openai: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("openai: %w", err)
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(data, &completion); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return Response{}, fmt.Errorf("openai: unexpected status %d", resp.StatusCode)
+		}
+		return Response{}, fmt.Errorf("openai: invalid response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if completion.Error != nil {
+			return Response{}, fmt.Errorf("openai: status %d: %s", resp.StatusCode, completion.Error.Message)
+		}
+		return Response{}, fmt.Errorf("openai: unexpected status %d", resp.StatusCode)
+	}
+	if len(completion.Choices) == 0 {
+		return Response{}, errors.New("openai: no content generated")
+	}
+
+	model := completion.Model
+	if model == "" {
+		model = p.model
+	}
+	return Response{Text: completion.Choices[0].Message.Content, Model: model}, nil
+}
@@ -0,0 +1,40 @@
+package llm
+
+import "unicode/utf8"
+
+// EstimateTokens は文字列のおおよそのトークン数を返します
+// 英数字は4文字で1トークン、日本語などそれ以外の文字は1文字で1トークンとして数えます
+func EstimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// TruncateToTokens は EstimateTokens で数えたトークン数が maxTokens 以下になるように末尾を切り詰めます
+func TruncateToTokens(s string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if EstimateTokens(s) <= maxTokens {
+		return s
+	}
+
+	ascii, other := 0, 0
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+		if (ascii+3)/4+other > maxTokens {
+			return s[:i]
+		}
+	}
+	return s
+}
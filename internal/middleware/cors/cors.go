@@ -13,6 +13,6 @@ func SetupCORS() echo.MiddlewareFunc {
 			echo.HeaderContentType,
 			echo.HeaderAccept,
 		},
-		AllowMethods: []string{"POST", "GET", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods: []string{"POST", "GET", "PUT", "PATCH", "DELETE", "OPTIONS"},
 	})
 }
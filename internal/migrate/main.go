@@ -3,6 +3,8 @@ package main
 import (
 	"SmartBook/internal/database"
 	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
 	"fmt"
 	"log"
 
@@ -21,6 +23,12 @@ func main() {
 		log.Fatalf("🔴 Error migrating User: %s", err)
 	}
 
+	// オンボーディングを追加する前に興味を登録していたユーザーは完了済みとする
+	err = dbConn.Exec("UPDATE users SET onboarded_at = updated_at WHERE onboarded_at IS NULL AND cardinality(interests) > 0").Error
+	if err != nil {
+		log.Fatalf("🔴 Error backfilling onboarded_at: %s", err)
+	}
+
 	err = dbConn.AutoMigrate(&model.ArticleData{})
 	if err != nil {
 		log.Fatalf("🔴 Error migrating ArticleData: %s", err)
@@ -31,6 +39,48 @@ func main() {
 		log.Fatalf("🔴 Error migrating MemoData: %s", err)
 	}
 
+	err = dbConn.AutoMigrate(&model.FeedSource{})
+	if err != nil {
+		log.Fatalf("🔴 Error migrating FeedSource: %s", err)
+	}
+
+	err = dbConn.AutoMigrate(&model.ArticleContent{})
+	if err != nil {
+		log.Fatalf("🔴 Error migrating ArticleContent: %s", err)
+	}
+
+	err = dbConn.AutoMigrate(&model.InteractionEvent{})
+	if err != nil {
+		log.Fatalf("🔴 Error migrating InteractionEvent: %s", err)
+	}
+
+	err = dbConn.AutoMigrate(&model.SavedSearch{}, &model.SavedSearchMatch{})
+	if err != nil {
+		log.Fatalf("🔴 Error migrating SavedSearch: %s", err)
+	}
+
+	err = dbConn.AutoMigrate(&model.ArticleSimilarity{})
+	if err != nil {
+		log.Fatalf("🔴 Error migrating ArticleSimilarity: %s", err)
+	}
+
+	// 検索用ベクトルが未作成か、古い作り方で作成された記事とメモのベクトルを作り直す
+	err = repository.NewArticleRepository(dbConn).RebuildSearchIndex(context.Background())
+	if err != nil {
+		log.Fatalf("🔴 Error rebuilding search index: %s", err)
+	}
+
+	// 同じ記事をまとめるための正規化したURLが未設定の記事に設定する
+	err = repository.NewArticleRepository(dbConn).RebuildCanonicalURLs(context.Background())
+	if err != nil {
+		log.Fatalf("🔴 Error rebuilding canonical URLs: %s", err)
+	}
+
+	err = repository.RebuildMemoSearchIndex(context.Background(), dbConn)
+	if err != nil {
+		log.Fatalf("🔴 Error rebuilding memo search index: %s", err)
+	}
+
 	// マイグレーション後にテストデータを挿入
 	insertTestData(dbConn)
 	fmt.Println("🟢 Successfully inserted test data")
@@ -5,25 +5,45 @@ import (
 )
 
 type User struct {
-	ID          string     `json:"id" gorm:"type:varchar(255);primaryKey"`
-	Name        string     `json:"name" gorm:"type:varchar(255);not null"`
-	Email       string     `json:"email" gorm:"type:varchar(255);unique;not null"`
-	Password    string     `json:"password" gorm:"type:varchar(255);not null"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null"`
-	Memos       []MemoData `json:"memos" gorm:"foreignKey:UserID"`
-	Interests   []string   `json:"interests" gorm:"type:varchar(255)[]"`
-	RecentViews []string   `json:"recent_views" gorm:"type:varchar(255)[]"`
-	Likes       []string   `json:"likes" gorm:"type:varchar(255)[]"`
+	ID          string      `json:"id" gorm:"type:varchar(255);primaryKey"`
+	Name        string      `json:"name" gorm:"type:varchar(255);not null"`
+	Email       string      `json:"email" gorm:"type:varchar(255);unique;not null"`
+	Password    string      `json:"password" gorm:"type:varchar(255);not null"`
+	CreatedAt   time.Time   `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time   `json:"updated_at" gorm:"not null"`
+	Memos       []MemoData  `json:"memos" gorm:"foreignKey:UserID"`
+	Interests   StringArray `json:"interests" gorm:"type:varchar(255)[]"`
+	RecentViews StringArray `json:"recent_views" gorm:"type:varchar(255)[]"`
+	Likes       StringArray `json:"likes" gorm:"type:varchar(255)[]"`
+	// OnboardedAt は新規登録後に興味を選んでオンボーディングを完了した日時です
+	OnboardedAt *time.Time `json:"onboarded_at"`
 }
 
+// NeedsOnboarding は興味を選ぶオンボーディングが完了していないかを返します
+func (u User) NeedsOnboarding() bool {
+	return u.OnboardedAt == nil
+}
+
+// ArticleData は取り込んだ記事です
+// URL・タイトル・著者・タグはソースによって長さの上限がなく、1件でも長すぎるとソースの取り込み全体が失敗するため text とします
 type ArticleData struct {
-	ID        string     `json:"id" gorm:"type:varchar(255);primaryKey"`
-	URL       string     `json:"url" gorm:"type:varchar(1000);not null"`
-	Title     string     `json:"title" gorm:"type:varchar(255);not null"`
-	Author    string     `json:"author" gorm:"type:varchar(255);not null"`
-	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
-	Memos     []MemoData `json:"memos" gorm:"foreignKey:ArticleID"`
+	ID        string      `json:"id" gorm:"type:varchar(255);primaryKey"`
+	URL       string      `json:"url" gorm:"type:text;not null"`
+	Title     string      `json:"title" gorm:"type:text;not null"`
+	Author    string      `json:"author" gorm:"type:text;not null"`
+	Score     int         `json:"score" gorm:"not null;default:0"`
+	Source    string      `json:"source" gorm:"type:varchar(255);not null;default:''"`
+	Tags      StringArray `json:"tags" gorm:"type:text[]"`
+	CreatedAt time.Time   `json:"created_at" gorm:"not null;index"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Memos     []MemoData  `json:"memos" gorm:"foreignKey:ArticleID"`
+	// CanonicalURL は正規化したURLで、複数のソースに掲載された同じ記事をまとめるために使います
+	// ArticleRepository が保存時に設定します (repository.ArticleCanonicalKey)
+	CanonicalURL string `json:"-" gorm:"type:text;not null;default:'';index"`
+	// SearchVector は全文検索用のベクトルで、ArticleRepository が作成します
+	SearchVector string `json:"-" gorm:"type:tsvector;->:false;<-:false;index:idx_article_data_search_vector,type:gin"`
+	// SearchVersion は SearchVector を作成した時の作り方のバージョンです
+	SearchVersion int `json:"-" gorm:"not null;default:0;<-:false"`
 }
 
 type MemoData struct {
@@ -35,4 +55,76 @@ type MemoData struct {
 	UpdatedAt time.Time   `json:"updated_at" gorm:"not null"`
 	User      User        `gorm:"foreignKey:UserID"`
 	Article   ArticleData `gorm:"foreignKey:ArticleID"`
+	// SearchVector はメモ検索用のベクトルで、MemoUseCase が保存時に作成します
+	SearchVector  string `json:"-" gorm:"type:tsvector;->:false;<-:false;index:idx_memo_data_search_vector,type:gin"`
+	SearchVersion int    `json:"-" gorm:"not null;default:0;<-:false"`
+}
+
+type FeedSource struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(255);unique;not null"`
+	URL       string    `json:"url" gorm:"type:varchar(1000);unique;not null"`
+	Enabled   bool      `json:"enabled" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+type ArticleContent struct {
+	ArticleID   string    `json:"article_id" gorm:"type:varchar(255);primaryKey"`
+	URL         string    `json:"url" gorm:"type:varchar(1000);not null"`
+	Title       string    `json:"title" gorm:"type:text;not null;default:''"`
+	Text        string    `json:"text" gorm:"type:text;not null"`
+	HTML        string    `json:"html" gorm:"type:text;not null"`
+	ExtractedAt time.Time `json:"extracted_at" gorm:"not null"`
+}
+
+type SavedSearch struct {
+	ID     int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID string `json:"user_id" gorm:"type:varchar(255);not null;index"`
+	Name   string `json:"name" gorm:"type:varchar(255);not null"`
+	Query  string `json:"query" gorm:"type:text;not null"`
+	// LastCheckedAt と LastCheckedArticleID は最後に確認した一致の (一致した日時, 記事ID) です
+	// これより後の一致を未読とします。同じ日時に一致した記事が複数ある場合も記事IDで区別します
+	LastCheckedAt        time.Time `json:"last_checked_at" gorm:"not null"`
+	LastCheckedArticleID string    `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt            time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt            time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// SavedSearchMatch は取り込み時に保存した検索条件に一致した記事です
+type SavedSearchMatch struct {
+	SavedSearchID int         `json:"saved_search_id" gorm:"primaryKey"`
+	ArticleID     string      `json:"article_id" gorm:"type:varchar(255);primaryKey"`
+	MatchedAt     time.Time   `json:"matched_at" gorm:"not null;index"`
+	SavedSearch   SavedSearch `json:"-" gorm:"foreignKey:SavedSearchID;constraint:OnDelete:CASCADE"`
+	Article       ArticleData `json:"-" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+}
+
+// InteractionEvent はユーザーの記事の閲覧・いいねの記録です
+// User の RecentViews と Likes はこの記録から作成します
+type InteractionEvent struct {
+	ID        int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    string `json:"user_id" gorm:"type:varchar(255);not null;index:idx_interaction_events_user_type,priority:1"`
+	ArticleID string `json:"article_id" gorm:"type:varchar(255);not null;index"`
+	EventType string `json:"event_type" gorm:"type:varchar(32);not null;index:idx_interaction_events_user_type,priority:2"`
+	// DwellSeconds は閲覧した秒数です。閲覧以外のイベントや不明な場合は nil です
+	DwellSeconds *int      `json:"dwell_seconds,omitempty"`
+	CreatedAt    time.Time `json:"created_at" gorm:"not null;index"`
+}
+
+const (
+	InteractionView   = "view"
+	InteractionLike   = "like"
+	InteractionUnlike = "unlike"
+)
+
+// ArticleSimilarity は同じユーザーに保存 (メモ・いいね) された記事同士の類似度です
+// CollaborativeUseCase が定期的に全体を作り直します
+type ArticleSimilarity struct {
+	ArticleID        string  `json:"article_id" gorm:"type:varchar(255);primaryKey"`
+	RelatedArticleID string  `json:"related_article_id" gorm:"type:varchar(255);primaryKey"`
+	Score            float64 `json:"score" gorm:"not null"`
+	// CoCount は両方の記事を保存したユーザーの数です
+	CoCount   int       `json:"co_count" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
 }
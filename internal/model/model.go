@@ -17,6 +17,9 @@ type Article struct {
 	CreatedAt time.Time `json:"created_at"`
 	Source    string    `json:"source"`
 	Tags      []string  `json:"tags"`
+	// Sources と DuplicateIDs は同じURLの記事を複数のソースからまとめた場合に設定されます
+	Sources      []string `json:"sources,omitempty"`
+	DuplicateIDs []string `json:"duplicate_ids,omitempty"`
 }
 
 type InputUser struct {
@@ -24,3 +27,209 @@ type InputUser struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+// UserProfile は /users/me で返すユーザー情報です。パスワードは含めません
+type UserProfile struct {
+	ID                 string     `json:"id"`
+	Name               string     `json:"name"`
+	Email              string     `json:"email"`
+	Interests          []string   `json:"interests"`
+	RecentViews        []string   `json:"recent_views"`
+	Likes              []string   `json:"likes"`
+	OnboardingRequired bool       `json:"onboarding_required"`
+	OnboardedAt        *time.Time `json:"onboarded_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// UserProfileUpdate は PATCH /users/me のリクエストです。指定した項目のみ更新します
+type UserProfileUpdate struct {
+	Name      *string   `json:"name"`
+	Email     *string   `json:"email"`
+	Interests *[]string `json:"interests"`
+}
+
+type SourceStatus struct {
+	Name                string      `json:"name"`
+	LastRunAt           time.Time   `json:"last_run_at"`
+	LastSuccessAt       time.Time   `json:"last_success_at"`
+	LastDuration        string      `json:"last_duration"`
+	LastCount           int         `json:"last_count"`
+	LastError           string      `json:"last_error,omitempty"`
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	LastFetchStats      *FetchStats `json:"last_fetch_stats,omitempty"`
+}
+
+type FetchStats struct {
+	Requested    int    `json:"requested"`
+	Fetched      int    `json:"fetched"`
+	Dropped      int    `json:"dropped"`
+	TotalDropped int64  `json:"total_dropped"`
+	SampleError  string `json:"sample_error,omitempty"`
+}
+
+type SourceFailure struct {
+	Name          string    `json:"name"`
+	Error         string    `json:"error"`
+	LastSuccessAt time.Time `json:"last_success_at"`
+}
+
+type ArticleListResponse struct {
+	Articles      []Article       `json:"articles"`
+	FailedSources []SourceFailure `json:"failed_sources"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+}
+
+// ArticleSearchHit は検索に一致した記事と、スニペットの作成に使う抽出済みの本文です
+type ArticleSearchHit struct {
+	Article Article
+	Body    string
+}
+
+// SearchHighlights は検索語に一致した箇所を <mark> で囲んだ HTML です。一致しない項目は省略します
+type SearchHighlights struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type SearchResult struct {
+	Article
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// 推薦の理由の種類
+const (
+	ReasonInterest       = "interest"
+	ReasonSimilarToLiked = "similar_to_liked"
+	ReasonTrending       = "trending"
+	ReasonPopular        = "popular"
+	ReasonContent        = "content"
+	ReasonAlsoSaved      = "also_saved"
+	ReasonAI             = "ai"
+)
+
+// RecommendationReason はおすすめ記事を推薦した理由です
+type RecommendationReason struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	// Interest は一致したユーザーの興味です (interest)
+	Interest string `json:"interest,omitempty"`
+	// Tag は急上昇しているタグです (trending)
+	Tag string `json:"tag,omitempty"`
+	// ArticleID と ArticleTitle は似ている、いいねした記事です (similar_to_liked)
+	// also_saved の場合は、一緒に保存されているユーザーが保存した記事です
+	ArticleID    string `json:"article_id,omitempty"`
+	ArticleTitle string `json:"article_title,omitempty"`
+	// Terms はユーザーが読んだ記事と共通する語です (content)
+	Terms []string `json:"terms,omitempty"`
+	// Confidence は LLM が返した確信度 (0〜1) です (ai)
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+type RecommendedArticle struct {
+	Article
+	Reason RecommendationReason `json:"reason"`
+}
+
+// UserSave はユーザーがメモを書いたか、いいねした記事です
+type UserSave struct {
+	UserID    string
+	ArticleID string
+}
+
+// RelatedArticle は記事を保存したユーザーが他に保存した記事です
+type RelatedArticle struct {
+	Article
+	Score float64 `json:"score"`
+	// SavedBy は両方の記事を保存したユーザーの数です
+	SavedBy int `json:"saved_by"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacets は検索条件に一致する全ての記事のソース別・タグ別の件数です
+type SearchFacets struct {
+	Sources []FacetCount `json:"sources"`
+	Tags    []FacetCount `json:"tags"`
+}
+
+type SearchResponse struct {
+	Articles   []SearchResult `json:"articles"`
+	Total      int64          `json:"total"`
+	Facets     SearchFacets   `json:"facets"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// ArticleFilter は記事一覧・検索・おすすめで共通の絞り込み条件です
+// 同じ項目内の複数の値はいずれかに一致すればよく、項目同士は全て満たす必要があります
+type ArticleFilter struct {
+	Sources  []string
+	Tags     []string
+	Since    *time.Time
+	Until    *time.Time
+	MinScore *int
+	Limit    int
+	// Cursor は前のページの next_cursor です
+	Cursor string
+}
+
+// ArticleCursor は作成日時と ID によるページ位置です
+type ArticleCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type SourceInfo struct {
+	// Key は SOURCE_<KEY>_* 環境変数で設定を上書きする際の識別子です
+	Key          string       `json:"key"`
+	Name         string       `json:"name"`
+	IDPrefix     string       `json:"id_prefix"`
+	DefaultLimit int          `json:"default_limit"`
+	Enabled      bool         `json:"enabled"`
+	Weight       float64      `json:"weight"`
+	Interval     string       `json:"interval"`
+	Health       string       `json:"health"`
+	Status       SourceStatus `json:"status"`
+}
+
+type SavedSearchRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+type SavedSearchResponse struct {
+	SavedSearch
+	// UnreadCount は最後に確認した後に一致した記事の件数です
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type SavedSearchMatchArticle struct {
+	Article
+	MatchedAt time.Time `json:"matched_at"`
+}
+
+type SavedSearchMatchesResponse struct {
+	SavedSearch SavedSearchResponse       `json:"saved_search"`
+	Articles    []SavedSearchMatchArticle `json:"articles"`
+	// HasMore は返しきれなかった未読の一致が残っている場合に true になります
+	HasMore bool `json:"has_more"`
+}
+
+// MemoSearchHighlights は検索語に一致した箇所を <mark> で囲んだ HTML です。一致しない項目は省略します
+type MemoSearchHighlights struct {
+	Content      string `json:"content,omitempty"`
+	ArticleTitle string `json:"article_title,omitempty"`
+}
+
+type MemoSearchResult struct {
+	ID           int                  `json:"id"`
+	ArticleID    string               `json:"article_id"`
+	ArticleTitle string               `json:"article_title"`
+	Content      string               `json:"content"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	Highlights   MemoSearchHighlights `json:"highlights"`
+}
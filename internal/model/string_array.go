@@ -0,0 +1,100 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringArray は Postgres の配列型 (varchar[] / text[]) と []string を相互変換します
+// pgx の database/sql ドライバは配列を "{a,b}" 形式の文字列で返すため、その解析を担当します
+type StringArray []string
+
+// Value は StringArray を Postgres の配列リテラルに変換します
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, s := range a {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('"')
+		for _, r := range s {
+			if r == '"' || r == '\\' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		}
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
+
+// Scan は Postgres の配列リテラルを StringArray に変換します
+func (a *StringArray) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return a.parse(string(v))
+	case string:
+		return a.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringArray", src)
+	}
+}
+
+func (a *StringArray) parse(literal string) error {
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return fmt.Errorf("invalid array literal: %q", literal)
+	}
+
+	body := literal[1 : len(literal)-1]
+	result := StringArray{}
+	if body == "" {
+		*a = result
+		return nil
+	}
+
+	var sb strings.Builder
+	quoted := false
+	inQuotes := false
+	escaped := false
+	flush := func() {
+		elem := sb.String()
+		// クォートされていない NULL は空文字として扱う
+		if !quoted && strings.EqualFold(elem, "NULL") {
+			elem = ""
+		}
+		result = append(result, elem)
+		sb.Reset()
+		quoted = false
+	}
+
+	for _, r := range body {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case r == ',' && !inQuotes:
+			flush()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	flush()
+
+	*a = result
+	return nil
+}
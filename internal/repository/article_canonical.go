@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"net/url"
+	"strings"
+)
+
+// trackingParams は記事の同一性に関係しない計測用のクエリパラメータです
+// utm_ で始まるパラメータはこれとは別に全て取り除きます
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref":     true,
+	"ref_src": true,
+	"_hsenc":  true,
+	"_hsmkt":  true,
+}
+
+// CanonicalizeURL は同じ記事を指すURLが同じ文字列になるように正規化します
+// 正規化できないURLは空文字を返します
+func CanonicalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	// http と https は同じ記事とみなす
+	u.Scheme = "https"
+	u.User = nil
+	u.Fragment = ""
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = host + ":" + port
+	}
+	u.Host = host
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	// Encode はキーでソートするため、パラメータの順序の違いも吸収される
+	u.RawQuery = query.Encode()
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	return u.String()
+}
+
+// ArticleCanonicalKey は同じ記事をまとめるための canonical_url の値です
+// URL を正規化できない記事 (Ask HN など) は他の記事とまとめないよう、記事のIDから作ります
+func ArticleCanonicalKey(id string, rawURL string) string {
+	if canonical := CanonicalizeURL(rawURL); canonical != "" {
+		return canonical
+	}
+	return "id:" + id
+}
+
+// RebuildCanonicalURLs は canonical_url が未設定の記事に値を設定します
+// マイグレーション時に実行します
+func (r *ArticleRepository) RebuildCanonicalURLs(ctx context.Context) error {
+	db := r.db.WithContext(ctx)
+	for {
+		var rows []model.ArticleData
+		err := db.Select("id", "url").
+			Where("canonical_url = ''").
+			Limit(rebuildBatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		for _, row := range rows {
+			err := db.Model(&model.ArticleData{}).
+				Where("id = ?", row.ID).
+				UpdateColumn("canonical_url", ArticleCanonicalKey(row.ID, row.URL)).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/search"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IArticleRepository interface {
+	UpsertArticles(ctx context.Context, articles []model.Article) ([]string, error)
+	GetArticleByID(ctx context.Context, id string) (model.Article, error)
+	GetArticlesByIDs(ctx context.Context, ids []string) ([]model.Article, error)
+	GetRecentArticlesBySource(ctx context.Context, source string, limit int) ([]model.Article, error)
+	FindArticleGroups(ctx context.Context, filter model.ArticleFilter, cursor *model.ArticleCursor, limit int) ([][]model.Article, error)
+	SearchArticles(ctx context.Context, query *search.Query, filter model.ArticleFilter, offset int, limit int) ([]model.ArticleSearchHit, error)
+	CountSearchArticles(ctx context.Context, query *search.Query, filter model.ArticleFilter) (int64, error)
+	SearchFacets(ctx context.Context, query *search.Query, filter model.ArticleFilter, tagLimit int) (model.SearchFacets, error)
+	MatchArticleIDs(ctx context.Context, query *search.Query, ids []string) ([]string, error)
+	GetPopularTags(ctx context.Context, prefix string, limit int) ([]model.FacetCount, error)
+}
+
+type ArticleRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleRepository(db *gorm.DB) *ArticleRepository {
+	return &ArticleRepository{
+		db: db,
+	}
+}
+
+// UpsertArticles は取得した記事を保存します。既に存在する記事はスコアなどを最新の値で更新します
+// 作成日時は最初に保存した値のまま更新しません。日時が読めず取り込んだ時刻で補った記事が、
+// 取り込むたびに新着の先頭に移動したり、新着のカーソルがずれたりしないようにするためです
+// 新しく保存した (まだ存在していなかった) 記事のIDを返します
+func (r *ArticleRepository) UpsertArticles(ctx context.Context, articles []model.Article) ([]string, error) {
+	if len(articles) == 0 {
+		return nil, nil
+	}
+
+	// 同じバッチ内で ID が重複すると ON CONFLICT が失敗するため、後勝ちで重複を除く
+	indexByID := make(map[string]int, len(articles))
+	rows := make([]model.ArticleData, 0, len(articles))
+	now := time.Now()
+	for _, article := range articles {
+		row := toArticleData(article)
+		row.UpdatedAt = now
+		if i, ok := indexByID[row.ID]; ok {
+			rows[i] = row
+			continue
+		}
+		indexByID[row.ID] = len(rows)
+		rows = append(rows, row)
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var insertedIDs []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingIDs []string
+		if err := tx.Model(&model.ArticleData{}).Where("id IN ?", ids).Pluck("id", &existingIDs).Error; err != nil {
+			return err
+		}
+		existing := make(map[string]bool, len(existingIDs))
+		for _, id := range existingIDs {
+			existing[id] = true
+		}
+		for _, id := range ids {
+			if !existing[id] {
+				insertedIDs = append(insertedIDs, id)
+			}
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"url", "canonical_url", "title", "author", "score", "source", "tags", "updated_at",
+			}),
+		}).CreateInBatches(&rows, 100).Error
+		if err != nil {
+			return err
+		}
+
+		return RefreshArticleSearchVectors(tx, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return insertedIDs, nil
+}
+
+func (r *ArticleRepository) GetArticleByID(ctx context.Context, id string) (model.Article, error) {
+	var row model.ArticleData
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return model.Article{}, err
+	}
+
+	return toArticle(row), nil
+}
+
+// GetArticlesByIDs は指定した記事を取得します。存在しない記事は含まず、順序は保証しません
+func (r *ArticleRepository) GetArticlesByIDs(ctx context.Context, ids []string) ([]model.Article, error) {
+	if len(ids) == 0 {
+		return []model.Article{}, nil
+	}
+
+	var rows []model.ArticleData
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return toArticles(rows), nil
+}
+
+// GetRecentArticlesBySource は指定したソースの記事を作成日時の新しい順に取得します
+func (r *ArticleRepository) GetRecentArticlesBySource(ctx context.Context, source string, limit int) ([]model.Article, error) {
+	var rows []model.ArticleData
+	err := r.db.WithContext(ctx).
+		Where("source = ?", source).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return toArticles(rows), nil
+}
+
+// FindArticleGroups は絞り込み条件に一致する記事を、正規化したURLが同じ記事ごとにまとめて取得します
+// まとまりは最も新しい記事の (作成日時, ID) の新しい順に並べ、各まとまりの記事も新しい順に並べます
+// cursor は前のページの最後のまとまりの、最も新しい記事の位置です
+func (r *ArticleRepository) FindArticleGroups(ctx context.Context, filter model.ArticleFilter, cursor *model.ArticleCursor, limit int) ([][]model.Article, error) {
+	db := r.db.WithContext(ctx)
+
+	// まとまりごとに最も新しい記事を代表の位置とする
+	heads := applyArticleFilterConditions(db.Model(&model.ArticleData{}), filter).
+		Select("DISTINCT ON (canonical_url) canonical_url, created_at, id").
+		Order("canonical_url").
+		Order("created_at DESC").
+		Order("id DESC")
+
+	query := db.Table("(?) AS heads", heads)
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	var keys []string
+	err := query.
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Pluck("canonical_url", &keys).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return [][]model.Article{}, nil
+	}
+
+	var rows []model.ArticleData
+	err = applyArticleFilterConditions(db.Model(&model.ArticleData{}), filter).
+		Where("canonical_url IN ?", keys).
+		Order("created_at DESC").
+		Order("id DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string][]model.Article, len(keys))
+	for _, row := range rows {
+		members[row.CanonicalURL] = append(members[row.CanonicalURL], toArticle(row))
+	}
+	groups := make([][]model.Article, 0, len(keys))
+	for _, key := range keys {
+		if len(members[key]) > 0 {
+			groups = append(groups, members[key])
+		}
+	}
+	return groups, nil
+}
+
+// SearchArticles は検索クエリに一致する記事を関連度の高い順に返します
+// 全文検索の語はタイトル・タグ・著者・抽出した本文から検索します (英数字は前方一致、日本語は bigram の一致)
+// スニペットを作成できるように、抽出済みの本文も返します
+func (r *ArticleRepository) SearchArticles(ctx context.Context, query *search.Query, filter model.ArticleFilter, offset int, limit int) ([]model.ArticleSearchHit, error) {
+	db := r.searchScope(ctx, query, filter)
+
+	// 全文検索の語がない場合 (tag:go のみなど) は新しい順に並べる
+	if rankQuery := buildRankTSQuery(query); rankQuery != "" {
+		db = db.Order(gorm.Expr("ts_rank_cd(search_vector, CAST(? AS tsquery)) DESC", rankQuery))
+	}
+
+	var rows []struct {
+		model.ArticleData `gorm:"embedded"`
+		Body              string
+	}
+	err := db.
+		Select("article_data.*, coalesce((SELECT article_contents.text FROM article_contents WHERE article_contents.article_id = article_data.id), '') AS body").
+		Order("created_at DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]model.ArticleSearchHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, model.ArticleSearchHit{Article: toArticle(row.ArticleData), Body: row.Body})
+	}
+	return hits, nil
+}
+
+// CountSearchArticles は検索クエリに一致する記事の件数を返します
+func (r *ArticleRepository) CountSearchArticles(ctx context.Context, query *search.Query, filter model.ArticleFilter) (int64, error) {
+	var total int64
+	if err := r.searchScope(ctx, query, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SearchFacets は検索クエリに一致する記事のソース別・タグ別の件数を、件数の多い順に返します
+// タグは大文字小文字を区別せずに集計し、上位 tagLimit 件のみ返します
+func (r *ArticleRepository) SearchFacets(ctx context.Context, query *search.Query, filter model.ArticleFilter, tagLimit int) (model.SearchFacets, error) {
+	facets := model.SearchFacets{
+		Sources: []model.FacetCount{},
+		Tags:    []model.FacetCount{},
+	}
+
+	err := r.searchScope(ctx, query, filter).
+		Select("source AS value, count(*) AS count").
+		Group("source").
+		Order("count DESC, value").
+		Scan(&facets.Sources).Error
+	if err != nil {
+		return model.SearchFacets{}, err
+	}
+
+	err = r.searchScope(ctx, query, filter).
+		Joins("CROSS JOIN LATERAL unnest(article_data.tags) AS facet_tag").
+		Select("lower(facet_tag) AS value, count(DISTINCT article_data.id) AS count").
+		Group("lower(facet_tag)").
+		Order("count DESC, value").
+		Limit(tagLimit).
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return model.SearchFacets{}, err
+	}
+
+	return facets, nil
+}
+
+// MatchArticleIDs は指定した記事のうち、検索クエリに一致する記事のIDを返します
+func (r *ArticleRepository) MatchArticleIDs(ctx context.Context, query *search.Query, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var matched []string
+	err := r.searchScope(ctx, query, model.ArticleFilter{}).
+		Where("id IN ?", ids).
+		Pluck("id", &matched).Error
+	if err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// GetPopularTags は記事に付いたタグを大文字小文字を区別せずに集計し、記事の件数の多い順に返します
+// prefix を指定した場合はその文字列で始まるタグのみ返します
+func (r *ArticleRepository) GetPopularTags(ctx context.Context, prefix string, limit int) ([]model.FacetCount, error) {
+	db := r.db.WithContext(ctx).
+		Table("article_data").
+		Joins("CROSS JOIN LATERAL unnest(article_data.tags) AS tag").
+		Where("tag <> ''")
+	if prefix != "" {
+		db = db.Where("starts_with(lower(tag), ?)", strings.ToLower(prefix))
+	}
+
+	tags := []model.FacetCount{}
+	err := db.
+		Select("lower(tag) AS value, count(DISTINCT article_data.id) AS count").
+		Group("lower(tag)").
+		Order("count DESC, value").
+		Limit(limit).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// searchScope は検索クエリと絞り込み条件に一致する記事のクエリを作成します
+func (r *ArticleRepository) searchScope(ctx context.Context, query *search.Query, filter model.ArticleFilter) *gorm.DB {
+	condition, args := buildSearchCondition(query.Root)
+	return applyArticleFilterConditions(r.db.WithContext(ctx).Model(&model.ArticleData{}), filter).Where(condition, args...)
+}
+
+// applyArticleFilterConditions は絞り込み条件を WHERE 句に変換します
+func applyArticleFilterConditions(db *gorm.DB, filter model.ArticleFilter) *gorm.DB {
+	if len(filter.Sources) > 0 {
+		db = db.Where("source IN ?", filter.Sources)
+	}
+	if len(filter.Tags) > 0 {
+		tags := make([]string, 0, len(filter.Tags))
+		for _, tag := range filter.Tags {
+			tags = append(tags, strings.ToLower(tag))
+		}
+		db = db.Where("EXISTS (SELECT 1 FROM unnest(tags) AS tag WHERE lower(tag) IN ?)", tags)
+	}
+	if filter.Since != nil {
+		db = db.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		db = db.Where("created_at < ?", *filter.Until)
+	}
+	if filter.MinScore != nil {
+		db = db.Where("score >= ?", *filter.MinScore)
+	}
+
+	return db
+}
+
+func toArticleData(article model.Article) model.ArticleData {
+	return model.ArticleData{
+		ID:           article.ID,
+		URL:          article.URL,
+		CanonicalURL: ArticleCanonicalKey(article.ID, article.URL),
+		Title:        article.Title,
+		Author:       article.Author,
+		Score:        article.Score,
+		Source:       article.Source,
+		Tags:         model.StringArray(article.Tags),
+		CreatedAt:    article.CreatedAt,
+	}
+}
+
+func toArticle(row model.ArticleData) model.Article {
+	return model.Article{
+		ID:        row.ID,
+		Title:     row.Title,
+		URL:       row.URL,
+		Score:     row.Score,
+		Author:    row.Author,
+		CreatedAt: row.CreatedAt,
+		Source:    row.Source,
+		Tags:      []string(row.Tags),
+	}
+}
+
+func toArticles(rows []model.ArticleData) []model.Article {
+	articles := make([]model.Article, 0, len(rows))
+	for _, row := range rows {
+		articles = append(articles, toArticle(row))
+	}
+	return articles
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/search"
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// searchIndexVersion は検索用ベクトルの作り方のバージョンです
+// 分割方法を変えた場合は値を上げると、RebuildSearchIndex で全ての記事のベクトルが作り直されます
+const searchIndexVersion = 2
+
+// rebuildBatchSize は RebuildSearchIndex で一度に作り直す記事の件数です
+const rebuildBatchSize = 200
+
+// articleSearchSource は検索用ベクトルの作成に使う記事のデータです
+type articleSearchSource struct {
+	ID     string
+	Title  string
+	Author string
+	Tags   model.StringArray
+	Text   string
+}
+
+// RefreshArticleSearchVectors は指定した記事の検索用ベクトルを作り直します
+// タイトル > タグ > 著者 > 抽出した本文 の順に重みを付けます
+// 記事を保存したトランザクションの中で呼び出せるように *gorm.DB を受け取ります
+func RefreshArticleSearchVectors(db *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var rows []articleSearchSource
+	err := db.Table("article_data").
+		Select("article_data.id, article_data.title, article_data.author, article_data.tags, coalesce(article_contents.text, '') AS text").
+		Joins("LEFT JOIN article_contents ON article_contents.article_id = article_data.id").
+		Where("article_data.id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		vector := search.BuildVector(
+			search.WeightedText{Text: row.Title, Weight: 'A'},
+			search.WeightedText{Text: strings.Join(row.Tags, " "), Weight: 'B'},
+			search.WeightedText{Text: row.Author, Weight: 'C'},
+			search.WeightedText{Text: row.Text, Weight: 'D'},
+		)
+		err := db.Exec(
+			"UPDATE article_data SET search_vector = CAST(? AS tsvector), search_version = ? WHERE id = ?",
+			vector, searchIndexVersion, row.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebuildSearchIndex は検索用ベクトルが未作成か、古い作り方で作成された記事のベクトルを作り直します
+// マイグレーション時に実行します
+func (r *ArticleRepository) RebuildSearchIndex(ctx context.Context) error {
+	db := r.db.WithContext(ctx)
+	for {
+		var ids []string
+		err := db.Model(&model.ArticleData{}).
+			Where("search_vector IS NULL OR search_version < ?", searchIndexVersion).
+			Limit(rebuildBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := RefreshArticleSearchVectors(db, ids); err != nil {
+			return err
+		}
+	}
+}
+
+// buildSearchCondition は検索クエリの構文木を WHERE 句に変換します
+func buildSearchCondition(node search.Node) (string, []interface{}) {
+	switch n := node.(type) {
+	case *search.AndNode:
+		return joinSearchConditions(n.Children, " AND ")
+
+	case *search.OrNode:
+		return joinSearchConditions(n.Children, " OR ")
+
+	case *search.NotNode:
+		sql, args := buildSearchCondition(n.Child)
+		return "NOT " + sql, args
+
+	case *search.CompareNode:
+		// Op はパーサーで > >= < <= = のいずれかに限定されている
+		return fmt.Sprintf("(score %s ?)", n.Op), []interface{}{n.Value}
+
+	case *search.TermNode:
+		return buildTermCondition(n)
+	}
+
+	return "TRUE", nil
+}
+
+func joinSearchConditions(children []search.Node, separator string) (string, []interface{}) {
+	parts := make([]string, 0, len(children))
+	var args []interface{}
+	for _, child := range children {
+		sql, childArgs := buildSearchCondition(child)
+		parts = append(parts, sql)
+		args = append(args, childArgs...)
+	}
+	return "(" + strings.Join(parts, separator) + ")", args
+}
+
+func buildTermCondition(term *search.TermNode) (string, []interface{}) {
+	switch term.Field {
+	case "tag":
+		return "EXISTS (SELECT 1 FROM unnest(tags) AS tag WHERE lower(tag) = lower(?))", []interface{}{term.Value}
+	case "source":
+		return "(lower(source) = lower(?))", []interface{}{term.Value}
+	case "author":
+		return "(lower(author) = lower(?))", []interface{}{term.Value}
+	}
+
+	// タイトルは重み A で索引している
+	weights := ""
+	if term.Field == "title" {
+		weights = "A"
+	}
+	var tsquery string
+	if term.Phrase {
+		tsquery = search.PhraseQuery(term.Value, weights)
+	} else {
+		tsquery = search.TermQuery(term.Value, weights)
+	}
+	if tsquery == "" {
+		// 記号のみの語は検索条件にしない
+		return "TRUE", nil
+	}
+	return "(search_vector @@ CAST(? AS tsquery))", []interface{}{tsquery}
+}
+
+// buildRankTSQuery は関連度の計算に使う tsquery を作成します
+// 否定されていない全文検索の語のいずれかを含むほど関連度が高くなります
+func buildRankTSQuery(query *search.Query) string {
+	var terms []string
+	for _, term := range query.TextTerms() {
+		if tsquery := search.TermQuery(term.Value, ""); tsquery != "" {
+			terms = append(terms, "("+tsquery+")")
+		}
+	}
+	return strings.Join(terms, " | ")
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type ICollaborativeRepository interface {
+	GetUserSaves(ctx context.Context) ([]model.UserSave, error)
+	ReplaceSimilarities(ctx context.Context, similarities []model.ArticleSimilarity) error
+	GetSimilarities(ctx context.Context, articleIDs []string) ([]model.ArticleSimilarity, error)
+	GetRelatedArticles(ctx context.Context, articleID string, limit int) ([]model.RelatedArticle, error)
+}
+
+type CollaborativeRepository struct {
+	db *gorm.DB
+}
+
+func NewCollaborativeRepository(db *gorm.DB) *CollaborativeRepository {
+	return &CollaborativeRepository{
+		db: db,
+	}
+}
+
+// GetUserSaves は全てのユーザーがメモを書いた記事といいねした記事を、重複を除いて返します
+func (r *CollaborativeRepository) GetUserSaves(ctx context.Context) ([]model.UserSave, error) {
+	var saves []model.UserSave
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT user_id, article_id FROM memo_data
+		UNION
+		SELECT users.id AS user_id, liked.article_id FROM users CROSS JOIN LATERAL unnest(users.likes) AS liked(article_id)
+	`).Scan(&saves).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return saves, nil
+}
+
+// ReplaceSimilarities は記事同士の類似度を全て削除して、指定した類似度に置き換えます
+func (r *CollaborativeRepository) ReplaceSimilarities(ctx context.Context, similarities []model.ArticleSimilarity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.ArticleSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&similarities, 500).Error
+	})
+}
+
+// GetSimilarities は指定した記事に似ている記事の類似度を返します
+func (r *CollaborativeRepository) GetSimilarities(ctx context.Context, articleIDs []string) ([]model.ArticleSimilarity, error) {
+	if len(articleIDs) == 0 {
+		return nil, nil
+	}
+
+	var similarities []model.ArticleSimilarity
+	err := r.db.WithContext(ctx).
+		Where("article_id IN ?", articleIDs).
+		Order("score DESC").
+		Find(&similarities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return similarities, nil
+}
+
+// GetRelatedArticles は記事を保存したユーザーが他に保存した記事を、類似度の高い順に返します
+func (r *CollaborativeRepository) GetRelatedArticles(ctx context.Context, articleID string, limit int) ([]model.RelatedArticle, error) {
+	var rows []struct {
+		model.ArticleData `gorm:"embedded"`
+		SimilarityScore   float64
+		CoCount           int
+	}
+	err := r.db.WithContext(ctx).
+		Table("article_similarities").
+		Select("article_data.*, article_similarities.score AS similarity_score, article_similarities.co_count").
+		Joins("JOIN article_data ON article_data.id = article_similarities.related_article_id").
+		Where("article_similarities.article_id = ?", articleID).
+		Order("article_similarities.score DESC").
+		Order("article_similarities.co_count DESC").
+		Order("article_data.created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]model.RelatedArticle, 0, len(rows))
+	for _, row := range rows {
+		related = append(related, model.RelatedArticle{
+			Article: toArticle(row.ArticleData),
+			Score:   row.SimilarityScore,
+			SavedBy: row.CoCount,
+		})
+	}
+	return related, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IContentRepository interface {
+	GetContent(ctx context.Context, articleID string) (model.ArticleContent, error)
+	SaveContent(ctx context.Context, content *model.ArticleContent) error
+	GetContentTexts(ctx context.Context, articleIDs []string) (map[string]string, error)
+}
+
+type ContentRepository struct {
+	db *gorm.DB
+}
+
+func NewContentRepository(db *gorm.DB) *ContentRepository {
+	return &ContentRepository{
+		db: db,
+	}
+}
+
+func (r *ContentRepository) GetContent(ctx context.Context, articleID string) (model.ArticleContent, error) {
+	var content model.ArticleContent
+	if err := r.db.WithContext(ctx).Where("article_id = ?", articleID).First(&content).Error; err != nil {
+		return model.ArticleContent{}, err
+	}
+
+	return content, nil
+}
+
+// SaveContent は抽出した本文を保存します。既に保存されている場合は上書きします
+// 本文も検索対象とするため、記事の検索用ベクトルも作り直します
+func (r *ContentRepository) SaveContent(ctx context.Context, content *model.ArticleContent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "article_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"url", "title", "text", "html", "extracted_at"}),
+		}).Create(content).Error
+		if err != nil {
+			return err
+		}
+
+		return RefreshArticleSearchVectors(tx, []string{content.ArticleID})
+	})
+}
+
+// GetContentTexts は指定した記事の抽出済みの本文を記事のIDごとに返します。本文を抽出していない記事は含みません
+func (r *ContentRepository) GetContentTexts(ctx context.Context, articleIDs []string) (map[string]string, error) {
+	texts := make(map[string]string, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return texts, nil
+	}
+
+	var rows []struct {
+		ArticleID string
+		Text      string
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.ArticleContent{}).
+		Select("article_id, text").
+		Where("article_id IN ?", articleIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		texts[row.ArticleID] = row.Text
+	}
+	return texts, nil
+}
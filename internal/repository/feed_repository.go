@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type IFeedRepository interface {
+	GetEnabledFeeds(ctx context.Context) ([]model.FeedSource, error)
+}
+
+type FeedRepository struct {
+	db *gorm.DB
+}
+
+func NewFeedRepository(db *gorm.DB) *FeedRepository {
+	return &FeedRepository{
+		db: db,
+	}
+}
+
+// GetEnabledFeeds は取り込み対象として有効になっている RSS/Atom フィードを取得します
+func (r *FeedRepository) GetEnabledFeeds(ctx context.Context) ([]model.FeedSource, error) {
+	var feeds []model.FeedSource
+	if err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&feeds).Error; err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
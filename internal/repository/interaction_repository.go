@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxRecentViews は User.RecentViews に保持する記事の数です
+const maxRecentViews = 50
+
+type IInteractionRepository interface {
+	RecordInteraction(ctx context.Context, event *model.InteractionEvent) error
+	SetLike(ctx context.Context, userID string, articleID string, liked bool) error
+}
+
+type InteractionRepository struct {
+	db *gorm.DB
+}
+
+func NewInteractionRepository(db *gorm.DB) *InteractionRepository {
+	return &InteractionRepository{
+		db: db,
+	}
+}
+
+// RecordInteraction はイベントを記録し、ユーザーの RecentViews と Likes を記録から作り直します
+func (r *InteractionRepository) RecordInteraction(ctx context.Context, event *model.InteractionEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return recordInteraction(tx, event)
+	})
+}
+
+// SetLike は記事のいいねを設定または解除します。既に同じ状態の場合は何も記録しません
+// 同時に届いたいいね・いいね解除が重複したり矛盾したりしないよう、ユーザーの行をロックしてから状態を確認して記録します
+func (r *InteractionRepository) SetLike(ctx context.Context, userID string, articleID string, liked bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", userID).
+			First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		current, err := isLiked(tx, userID, articleID)
+		if err != nil {
+			return err
+		}
+		if current == liked {
+			return nil
+		}
+
+		eventType := model.InteractionUnlike
+		if liked {
+			eventType = model.InteractionLike
+		}
+		return recordInteraction(tx, &model.InteractionEvent{
+			UserID:    userID,
+			ArticleID: articleID,
+			EventType: eventType,
+			CreatedAt: time.Now(),
+		})
+	})
+}
+
+func recordInteraction(tx *gorm.DB, event *model.InteractionEvent) error {
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+
+	recentViews := []string{}
+	err := tx.Model(&model.InteractionEvent{}).
+		Where("user_id = ? AND event_type = ?", event.UserID, model.InteractionView).
+		Group("article_id").
+		Order("max(created_at) DESC").
+		Limit(maxRecentViews).
+		Pluck("article_id", &recentViews).Error
+	if err != nil {
+		return err
+	}
+
+	likes, err := likedArticleIDs(tx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&model.User{}).
+		Where("id = ?", event.UserID).
+		Updates(map[string]interface{}{
+			"recent_views": nonNullArray(recentViews),
+			"likes":        likes,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// isLiked は記事の最新のいいね・いいね解除のイベントがいいねであるかを返します
+func isLiked(db *gorm.DB, userID string, articleID string) (bool, error) {
+	var events []model.InteractionEvent
+	err := db.
+		Where("user_id = ? AND article_id = ? AND event_type IN ?", userID, articleID, []string{model.InteractionLike, model.InteractionUnlike}).
+		Order("created_at DESC, id DESC").
+		Limit(1).
+		Find(&events).Error
+	if err != nil {
+		return false, err
+	}
+
+	return len(events) > 0 && events[0].EventType == model.InteractionLike, nil
+}
+
+// likedArticleIDs は最新のイベントがいいねである記事のIDを、いいねした新しい順に返します
+func likedArticleIDs(db *gorm.DB, userID string) (model.StringArray, error) {
+	likes := []string{}
+	err := db.Raw(`
+		SELECT article_id FROM (
+			SELECT DISTINCT ON (article_id) article_id, event_type, created_at
+			FROM interaction_events
+			WHERE user_id = ? AND event_type IN ?
+			ORDER BY article_id, created_at DESC, id DESC
+		) AS latest
+		WHERE event_type = ?
+		ORDER BY created_at DESC`,
+		userID, []string{model.InteractionLike, model.InteractionUnlike}, model.InteractionLike,
+	).Scan(&likes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return nonNullArray(likes), nil
+}
+
+// nonNullArray は空の場合も NULL ではなく空の配列として保存されるように変換します
+func nonNullArray(values []string) model.StringArray {
+	if values == nil {
+		return model.StringArray{}
+	}
+	return model.StringArray(values)
+}
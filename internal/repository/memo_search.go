@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/search"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// memoSearchSource はメモの検索用ベクトルの作成に使うデータです
+type memoSearchSource struct {
+	ID           int
+	Content      string
+	ArticleTitle string
+}
+
+// RefreshMemoSearchVectors は指定したメモの検索用ベクトルを作り直します
+// メモの本文 > 記事のタイトル の順に重みを付けます
+func RefreshMemoSearchVectors(db *gorm.DB, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var rows []memoSearchSource
+	err := db.Table("memo_data").
+		Select("memo_data.id, memo_data.content, coalesce(article_data.title, '') AS article_title").
+		Joins("LEFT JOIN article_data ON article_data.id = memo_data.article_id").
+		Where("memo_data.id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		vector := search.BuildVector(
+			search.WeightedText{Text: row.Content, Weight: 'A'},
+			search.WeightedText{Text: row.ArticleTitle, Weight: 'B'},
+		)
+		err := db.Exec(
+			"UPDATE memo_data SET search_vector = CAST(? AS tsvector), search_version = ? WHERE id = ?",
+			vector, searchIndexVersion, row.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebuildMemoSearchIndex は検索用ベクトルが未作成か、古い作り方で作成されたメモのベクトルを作り直します
+// マイグレーション時に実行します
+func RebuildMemoSearchIndex(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	for {
+		var ids []int
+		err := db.Model(&model.MemoData{}).
+			Where("search_vector IS NULL OR search_version < ?", searchIndexVersion).
+			Limit(rebuildBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := RefreshMemoSearchVectors(db, ids); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ISavedSearchRepository interface {
+	CreateSavedSearch(ctx context.Context, savedSearch *model.SavedSearch) error
+	GetSavedSearches(ctx context.Context, userID string) ([]model.SavedSearch, error)
+	GetAllSavedSearches(ctx context.Context) ([]model.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, userID string, id int) (model.SavedSearch, error)
+	CountSavedSearches(ctx context.Context, userID string) (int64, error)
+	UpdateSavedSearch(ctx context.Context, savedSearch *model.SavedSearch, resetMatches bool) error
+	DeleteSavedSearch(ctx context.Context, userID string, id int) error
+	SaveMatches(ctx context.Context, savedSearchID int, articleIDs []string, matchedAt time.Time) error
+	CountUnreadMatches(ctx context.Context, userID string) (map[int]int64, error)
+	GetMatches(ctx context.Context, savedSearchID int, limit int) ([]model.SavedSearchMatchArticle, error)
+	GetUnreadMatches(ctx context.Context, savedSearch model.SavedSearch, limit int) ([]model.SavedSearchMatchArticle, error)
+	MarkChecked(ctx context.Context, id int, matchedAt time.Time, articleID string) error
+}
+
+type SavedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) *SavedSearchRepository {
+	return &SavedSearchRepository{
+		db: db,
+	}
+}
+
+func (r *SavedSearchRepository) CreateSavedSearch(ctx context.Context, savedSearch *model.SavedSearch) error {
+	return r.db.WithContext(ctx).Create(savedSearch).Error
+}
+
+func (r *SavedSearchRepository) GetSavedSearches(ctx context.Context, userID string) ([]model.SavedSearch, error) {
+	var savedSearches []model.SavedSearch
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&savedSearches).Error; err != nil {
+		return nil, err
+	}
+
+	return savedSearches, nil
+}
+
+// GetAllSavedSearches は全てのユーザーの保存した検索条件を取得します。取り込み時の再評価に使います
+func (r *SavedSearchRepository) GetAllSavedSearches(ctx context.Context) ([]model.SavedSearch, error) {
+	var savedSearches []model.SavedSearch
+	if err := r.db.WithContext(ctx).Order("id").Find(&savedSearches).Error; err != nil {
+		return nil, err
+	}
+
+	return savedSearches, nil
+}
+
+func (r *SavedSearchRepository) GetSavedSearch(ctx context.Context, userID string, id int) (model.SavedSearch, error) {
+	var savedSearch model.SavedSearch
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, id).First(&savedSearch).Error; err != nil {
+		return model.SavedSearch{}, err
+	}
+
+	return savedSearch, nil
+}
+
+func (r *SavedSearchRepository) CountSavedSearches(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateSavedSearch は名前とクエリを更新します
+// resetMatches が true の場合は以前のクエリで一致した記事を削除します
+func (r *SavedSearchRepository) UpdateSavedSearch(ctx context.Context, savedSearch *model.SavedSearch, resetMatches bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(savedSearch).
+			Select("name", "query", "last_checked_at", "last_checked_article_id", "updated_at").
+			Updates(savedSearch).Error
+		if err != nil {
+			return err
+		}
+
+		if !resetMatches {
+			return nil
+		}
+		return tx.Where("saved_search_id = ?", savedSearch.ID).Delete(&model.SavedSearchMatch{}).Error
+	})
+}
+
+// DeleteSavedSearch は保存した検索条件と、一致した記事の記録を削除します
+func (r *SavedSearchRepository) DeleteSavedSearch(ctx context.Context, userID string, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND id = ?", userID, id).Delete(&model.SavedSearch{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("saved_search_id = ?", id).Delete(&model.SavedSearchMatch{}).Error
+	})
+}
+
+// SaveMatches は検索条件に一致した記事を記録します。既に記録されている記事は無視します
+func (r *SavedSearchRepository) SaveMatches(ctx context.Context, savedSearchID int, articleIDs []string, matchedAt time.Time) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+
+	matches := make([]model.SavedSearchMatch, 0, len(articleIDs))
+	for _, articleID := range articleIDs {
+		matches = append(matches, model.SavedSearchMatch{
+			SavedSearchID: savedSearchID,
+			ArticleID:     articleID,
+			MatchedAt:     matchedAt,
+		})
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Omit("SavedSearch", "Article").
+		CreateInBatches(&matches, 100).Error
+}
+
+// CountUnreadMatches はユーザーの検索条件ごとに、最後に確認した後に一致した記事の件数を返します
+func (r *SavedSearchRepository) CountUnreadMatches(ctx context.Context, userID string) (map[int]int64, error) {
+	var rows []struct {
+		SavedSearchID int
+		Count         int64
+	}
+	err := r.db.WithContext(ctx).
+		Table("saved_search_matches").
+		Select("saved_search_matches.saved_search_id, count(*) AS count").
+		Joins("JOIN saved_searches ON saved_searches.id = saved_search_matches.saved_search_id").
+		Where("saved_searches.user_id = ?", userID).
+		Where("(saved_search_matches.matched_at, saved_search_matches.article_id) > (saved_searches.last_checked_at, saved_searches.last_checked_article_id)").
+		Group("saved_search_matches.saved_search_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.SavedSearchID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetMatches は検索条件に一致した記事を新しく一致した順に返します
+func (r *SavedSearchRepository) GetMatches(ctx context.Context, savedSearchID int, limit int) ([]model.SavedSearchMatchArticle, error) {
+	return r.findMatches(r.matchesScope(ctx, savedSearchID).
+		Order("saved_search_matches.matched_at DESC").
+		Order("saved_search_matches.article_id DESC").
+		Limit(limit))
+}
+
+// GetUnreadMatches は最後に確認した一致より後の一致を、古く一致した順に返します
+// 確認した位置を返した最後の一致まで進めることで、未読の一致を漏れなく1回ずつ返せます
+func (r *SavedSearchRepository) GetUnreadMatches(ctx context.Context, savedSearch model.SavedSearch, limit int) ([]model.SavedSearchMatchArticle, error) {
+	return r.findMatches(r.matchesScope(ctx, savedSearch.ID).
+		Where("(saved_search_matches.matched_at, saved_search_matches.article_id) > (?, ?)", savedSearch.LastCheckedAt, savedSearch.LastCheckedArticleID).
+		Order("saved_search_matches.matched_at ASC").
+		Order("saved_search_matches.article_id ASC").
+		Limit(limit))
+}
+
+func (r *SavedSearchRepository) matchesScope(ctx context.Context, savedSearchID int) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("saved_search_matches").
+		Select("article_data.*, saved_search_matches.matched_at").
+		Joins("JOIN article_data ON article_data.id = saved_search_matches.article_id").
+		Where("saved_search_matches.saved_search_id = ?", savedSearchID)
+}
+
+func (r *SavedSearchRepository) findMatches(db *gorm.DB) ([]model.SavedSearchMatchArticle, error) {
+	var rows []struct {
+		model.ArticleData `gorm:"embedded"`
+		MatchedAt         time.Time
+	}
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	articles := make([]model.SavedSearchMatchArticle, 0, len(rows))
+	for _, row := range rows {
+		articles = append(articles, model.SavedSearchMatchArticle{
+			Article:   toArticle(row.ArticleData),
+			MatchedAt: row.MatchedAt,
+		})
+	}
+	return articles, nil
+}
+
+// MarkChecked はユーザーが確認した最後の一致の (一致した日時, 記事ID) を記録します
+// 確認した位置が戻らないよう、記録されている位置より後の場合のみ更新します
+func (r *SavedSearchRepository) MarkChecked(ctx context.Context, id int, matchedAt time.Time, articleID string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.SavedSearch{}).
+		Where("id = ?", id).
+		Where("(last_checked_at, last_checked_article_id) < (?, ?)", matchedAt, articleID).
+		Updates(map[string]interface{}{
+			"last_checked_at":         matchedAt,
+			"last_checked_article_id": articleID,
+		}).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"errors"
+
+	"firebase.google.com/go/auth"
+	"gorm.io/gorm"
+)
+
+// ErrEmailAlreadyExists は変更先のメールアドレスを他のユーザーが使っている場合のエラーです
+var ErrEmailAlreadyExists = errors.New("email already exists")
+
+type IUserRepository interface {
+	GetUserByID(ctx context.Context, id string) (model.User, error)
+	UpdateUser(ctx context.Context, user *model.User) error
+	GetMemoArticleIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+type UserRepository struct {
+	db   *gorm.DB
+	auth *auth.Client
+}
+
+func NewUserRepository(db *gorm.DB, auth *auth.Client) *UserRepository {
+	return &UserRepository{
+		db:   db,
+		auth: auth,
+	}
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (model.User, error) {
+	var user model.User
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// UpdateUser は名前・メールアドレス・興味・オンボーディングの完了日時を更新します
+// 名前かメールアドレスを変更した場合は Firebase のユーザーも更新し、失敗した場合はデータベースの変更を取り消します
+func (r *UserRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current model.User
+		if err := tx.Where("id = ?", user.ID).First(&current).Error; err != nil {
+			return err
+		}
+
+		if user.Email != current.Email {
+			var count int64
+			err := tx.Model(&model.User{}).
+				Where("lower(email) = lower(?) AND id <> ?", user.Email, user.ID).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrEmailAlreadyExists
+			}
+		}
+
+		err := tx.Model(user).
+			Updates(map[string]interface{}{
+				"name":         user.Name,
+				"email":        user.Email,
+				"interests":    nonNullArray(user.Interests),
+				"onboarded_at": user.OnboardedAt,
+				"updated_at":   user.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		if user.Name == current.Name && user.Email == current.Email {
+			return nil
+		}
+		params := (&auth.UserToUpdate{}).DisplayName(user.Name)
+		if user.Email != current.Email {
+			params = params.Email(user.Email)
+		}
+		if _, err := r.auth.UpdateUser(ctx, user.ID, params); err != nil {
+			if auth.IsEmailAlreadyExists(err) {
+				return ErrEmailAlreadyExists
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// GetMemoArticleIDs はユーザーがメモを書いた記事のIDを、メモを新しく更新した順に返します
+func (r *UserRepository) GetMemoArticleIDs(ctx context.Context, userID string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&model.MemoData{}).
+		Select("article_id").
+		Where("user_id = ?", userID).
+		Group("article_id").
+		Order("max(updated_at) DESC").
+		Pluck("article_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,208 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// snippetContext はスニペットで一致箇所の前に含める文字数です
+	snippetContext = 40
+	markOpen       = "<mark>"
+	markClose      = "</mark>"
+)
+
+// HighlightTerms はハイライトに使う語を正規化して返します
+// 否定されていない全文検索の語と title: の語が対象です
+func (q *Query) HighlightTerms() []string {
+	var values []string
+	for _, term := range q.TextTerms() {
+		values = append(values, term.Value)
+	}
+	var walk func(n Node, negated bool)
+	walk = func(n Node, negated bool) {
+		switch n := n.(type) {
+		case *AndNode:
+			for _, c := range n.Children {
+				walk(c, negated)
+			}
+		case *OrNode:
+			for _, c := range n.Children {
+				walk(c, negated)
+			}
+		case *NotNode:
+			walk(n.Child, !negated)
+		case *TermNode:
+			if n.Field == "title" && !negated {
+				values = append(values, n.Value)
+			}
+		}
+	}
+	walk(q.Root, false)
+
+	return HighlightTermsOf(values...)
+}
+
+// HighlightTermsOf は検索語の文字列からハイライトに使う語を正規化して返します
+func HighlightTermsOf(values ...string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, group := range tokenGroups(value) {
+			term := joinGroup(group)
+			if term != "" && !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+	// 長い語を優先して一致させる
+	sort.SliceStable(terms, func(i, j int) bool {
+		return len([]rune(terms[i])) > len([]rune(terms[j]))
+	})
+	return terms
+}
+
+// joinGroup は bigram に分割した語を元の文字列に戻します
+func joinGroup(group tokenGroup) string {
+	if !group.cjk || len(group.tokens) == 0 {
+		return strings.Join(group.tokens, "")
+	}
+	var sb strings.Builder
+	sb.WriteString(group.tokens[0])
+	for _, token := range group.tokens[1:] {
+		runes := []rune(token)
+		sb.WriteRune(runes[len(runes)-1])
+	}
+	return sb.String()
+}
+
+// Highlight は text 中で terms に一致する箇所を <mark> で囲んだ HTML を返します
+// maxRunes が 0 より大きい場合は最初の一致箇所の周辺 maxRunes 文字のスニペットにします
+// 一致する箇所がない場合は空文字を返します
+func Highlight(text string, terms []string, maxRunes int) string {
+	original := []rune(text)
+	normalized, offsets := normalizeWithOffsets(original)
+
+	// 一致箇所を元の文字列での [start, end) の範囲で求める
+	var ranges [][2]int
+	for _, term := range terms {
+		termRunes := []rune(term)
+		if len(termRunes) == 0 {
+			continue
+		}
+		// 英数字の語は語の先頭からの前方一致のみとする
+		wordStart := !isCJK(termRunes[0])
+		for i := 0; i+len(termRunes) <= len(normalized); i++ {
+			if !hasRunesAt(normalized, termRunes, i) {
+				continue
+			}
+			if wordStart && i > 0 && isWordRune(normalized[i-1]) && !isCJK(normalized[i-1]) {
+				continue
+			}
+			from, to := offsets[i], offsets[i+len(termRunes)]
+			if to <= from {
+				// 1文字が複数の文字に正規化された場合 (㍻ → 平成 など) はその文字全体を囲む
+				to = from + 1
+			}
+			ranges = append(ranges, [2]int{from, to})
+			i += len(termRunes) - 1
+		}
+	}
+	if len(ranges) == 0 {
+		return ""
+	}
+	ranges = mergeRanges(ranges)
+
+	start, end := 0, len(original)
+	if maxRunes > 0 && len(original) > maxRunes {
+		// 短いスニペットでも一致箇所が入るよう、前に含める文字数は maxRunes の 1/4 までにする
+		start = ranges[0][0] - min(snippetContext, maxRunes/4)
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxRunes
+		if end > len(original) {
+			end = len(original)
+			start = max(0, end-maxRunes)
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for _, r := range ranges {
+		if r[1] <= start || r[0] >= end {
+			continue
+		}
+		from, to := max(r[0], start), min(r[1], end)
+		sb.WriteString(html.EscapeString(string(original[pos:from])))
+		sb.WriteString(markOpen + html.EscapeString(string(original[from:to])) + markClose)
+		pos = to
+	}
+	sb.WriteString(html.EscapeString(string(original[pos:end])))
+	if end < len(original) {
+		sb.WriteString("…")
+	}
+
+	return strings.TrimSpace(collapseSpaces(sb.String()))
+}
+
+// normalizeWithOffsets は1文字ずつ正規化し、正規化後の各文字に対応する元の文字の位置を返します
+// offsets の最後の要素は元の文字列の長さです
+func normalizeWithOffsets(original []rune) ([]rune, []int) {
+	normalized := make([]rune, 0, len(original))
+	offsets := make([]int, 0, len(original)+1)
+	for i := 0; i < len(original); {
+		// 半角カナの濁点・半濁点 (ｶﾞ など) は前の文字と合わせて正規化する
+		end := i + 1
+		for end < len(original) && isSoundMark(original[end]) {
+			end++
+		}
+		for _, n := range Normalize(string(original[i:end])) {
+			normalized = append(normalized, n)
+			offsets = append(offsets, i)
+		}
+		i = end
+	}
+	offsets = append(offsets, len(original))
+	return normalized, offsets
+}
+
+func isSoundMark(r rune) bool {
+	return r == 0xFF9E || r == 0xFF9F || r == 0x3099 || r == 0x309A
+}
+
+func hasRunesAt(s []rune, sub []rune, i int) bool {
+	for j, r := range sub {
+		if s[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeRanges は重なっている範囲をまとめ、開始位置の順に並べます
+func mergeRanges(ranges [][2]int) [][2]int {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+	merged := [][2]int{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1] {
+			last[1] = max(last[1], r[1])
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
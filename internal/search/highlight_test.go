@@ -0,0 +1,167 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		maxRunes int
+		want     string
+	}{
+		{
+			name:  "full-width text matches a normalized term",
+			text:  "ＧＯで作るアプリ",
+			terms: HighlightTermsOf("go"),
+			want:  "<mark>ＧＯ</mark>で作るアプリ",
+		},
+		{
+			name:  "one rune expanded to several by NFKC is marked as a whole",
+			text:  "令和の前は㍻でした",
+			terms: HighlightTermsOf("平成"),
+			want:  "令和の前は<mark>㍻</mark>でした",
+		},
+		{
+			name:  "half-width kana with dakuten",
+			text:  "ｶﾞｲﾄﾞを読む",
+			terms: HighlightTermsOf("ガイド"),
+			want:  "<mark>ｶﾞｲﾄﾞ</mark>を読む",
+		},
+		{
+			name:  "half-width dakuten in the middle of a match",
+			text:  "ﾃﾞｰﾀﾍﾞｰｽ入門",
+			terms: HighlightTermsOf("べーす"),
+			want:  "ﾃﾞｰﾀ<mark>ﾍﾞｰｽ</mark>入門",
+		},
+		{
+			name:  "HTML around the mark is escaped",
+			text:  "<b>Go</b> & Rust",
+			terms: HighlightTermsOf("go"),
+			want:  "&lt;b&gt;<mark>Go</mark>&lt;/b&gt; &amp; Rust",
+		},
+		{
+			name:  "HTML inside the mark is escaped",
+			text:  "use a<b in Go",
+			terms: HighlightTermsOf("a<b"),
+			want:  "use <mark>a</mark>&lt;<mark>b</mark> in Go",
+		},
+		{
+			name:  "words match by prefix only",
+			text:  "ergo golang go",
+			terms: HighlightTermsOf("go"),
+			want:  "ergo <mark>go</mark>lang <mark>go</mark>",
+		},
+		{
+			name:  "CJK terms match anywhere",
+			text:  "全文検索エンジン",
+			terms: HighlightTermsOf("検索"),
+			want:  "全文<mark>検索</mark>エンジン",
+		},
+		{
+			name:  "overlapping matches are merged",
+			text:  "検索エンジン",
+			terms: HighlightTermsOf("検索", "索エン"),
+			want:  "<mark>検索エン</mark>ジン",
+		},
+		{
+			name:  "no match",
+			text:  "Rust",
+			terms: HighlightTermsOf("go"),
+			want:  "",
+		},
+		{
+			name:  "empty terms are skipped",
+			text:  "Go",
+			terms: []string{"", "go"},
+			want:  "<mark>Go</mark>",
+		},
+		{
+			name:     "snippet around a match in the middle has ellipses on both sides",
+			text:     "aaaaaaaaaa bbbbbbbbbb cccccccccc dddddddddd eeeeeeeeee target ffffffffff gggggggggg",
+			terms:    HighlightTermsOf("target"),
+			maxRunes: 30,
+			want:     "…eeeeee <mark>target</mark> ffffffffff ggggg…",
+		},
+		{
+			name:     "snippet at the start has an ellipsis only at the end",
+			text:     "target at start and then lots of text after it",
+			terms:    HighlightTermsOf("target"),
+			maxRunes: 20,
+			want:     "<mark>target</mark> at start and …",
+		},
+		{
+			name:     "snippet at the end has an ellipsis only at the start",
+			text:     "lots of text before it and then the target",
+			terms:    HighlightTermsOf("target"),
+			maxRunes: 20,
+			want:     "… and then the <mark>target</mark>",
+		},
+		{
+			name:     "text shorter than the snippet is returned whole",
+			text:     "short target text",
+			terms:    HighlightTermsOf("target"),
+			maxRunes: 100,
+			want:     "short <mark>target</mark> text",
+		},
+		{
+			name:     "whitespace is collapsed",
+			text:     "line one\n\n  target\tline",
+			terms:    HighlightTermsOf("target"),
+			maxRunes: 0,
+			want:     "line one <mark>target</mark> line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.terms, tt.maxRunes); got != tt.want {
+				t.Errorf("Highlight(%q, %q, %d) = %q, want %q", tt.text, tt.terms, tt.maxRunes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightSnippetLength(t *testing.T) {
+	text := strings.Repeat("あ", 200) + "検索" + strings.Repeat("い", 200)
+	got := Highlight(text, HighlightTermsOf("検索"), 100)
+	plain := strings.NewReplacer(markOpen, "", markClose, "", "…", "").Replace(got)
+	if n := len([]rune(plain)); n != 100 {
+		t.Errorf("snippet has %d runes, want 100", n)
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>検索</mark>") {
+		t.Errorf("unexpected snippet %q", got)
+	}
+}
+
+func TestNormalizeWithOffsets(t *testing.T) {
+	tests := []struct {
+		input      string
+		normalized string
+		offsets    []int
+	}{
+		{"ＧＯ", "go", []int{0, 1, 2}},
+		{"㍻x", "平成x", []int{0, 0, 1, 2}},
+		{"ｶﾞｲ", "がい", []int{0, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		normalized, offsets := normalizeWithOffsets([]rune(tt.input))
+		if string(normalized) != tt.normalized || !reflect.DeepEqual(offsets, tt.offsets) {
+			t.Errorf("normalizeWithOffsets(%q) = %q %v, want %q %v", tt.input, string(normalized), offsets, tt.normalized, tt.offsets)
+		}
+	}
+}
+
+func TestHighlightTermsOf(t *testing.T) {
+	// 長い語から順に、正規化して重複を除く
+	got := HighlightTermsOf("Go", "ＧＯ", "検索エンジン", "!!!")
+	want := []string{"検索えんじん", "go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HighlightTermsOf = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,440 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 検索クエリの構文
+//
+//	query   := or
+//	or      := and ("OR" and)*
+//	and     := unary+
+//	unary   := "-" unary | primary
+//	primary := "(" or ")" | field | word | "\"phrase\""
+//	field   := name ":" value | name op number   (op: > >= < <= =)
+//
+// 例: tag:go source:"Hacker News" author:pg score>100 -crypto (rust OR zig)
+
+// Fields は検索クエリで使えるフィールドです
+var Fields = map[string]bool{
+	"tag":    true,
+	"source": true,
+	"author": true,
+	"title":  true,
+	"score":  true,
+}
+
+// numericFields は比較演算子を使えるフィールドです
+var numericFields = map[string]bool{
+	"score": true,
+}
+
+// Node は検索クエリの構文木のノードです
+type Node interface {
+	node()
+}
+
+// AndNode は全ての子ノードに一致する条件です
+type AndNode struct {
+	Children []Node
+}
+
+// OrNode はいずれかの子ノードに一致する条件です
+type OrNode struct {
+	Children []Node
+}
+
+// NotNode は子ノードに一致しない条件です
+type NotNode struct {
+	Child Node
+}
+
+// TermNode は語句による条件です。Field が空の場合は全文検索を表します
+type TermNode struct {
+	Field  string
+	Value  string
+	Phrase bool
+	Pos    int
+}
+
+// CompareNode は数値フィールドの比較条件です
+type CompareNode struct {
+	Field string
+	Op    string
+	Value int
+	Pos   int
+}
+
+func (*AndNode) node()     {}
+func (*OrNode) node()      {}
+func (*NotNode) node()     {}
+func (*TermNode) node()    {}
+func (*CompareNode) node() {}
+
+// Query は解析済みの検索クエリです
+type Query struct {
+	Root Node
+}
+
+// ParseError は検索クエリの構文エラーです。Pos はクエリ先頭からの文字 (rune) 単位の位置です
+type ParseError struct {
+	Pos     int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+// TextTerms は否定されていない全文検索の語句を返します。関連度の計算に使います
+func (q *Query) TextTerms() []*TermNode {
+	var terms []*TermNode
+	var walk func(n Node, negated bool)
+	walk = func(n Node, negated bool) {
+		switch n := n.(type) {
+		case *AndNode:
+			for _, c := range n.Children {
+				walk(c, negated)
+			}
+		case *OrNode:
+			for _, c := range n.Children {
+				walk(c, negated)
+			}
+		case *NotNode:
+			walk(n.Child, !negated)
+		case *TermNode:
+			if n.Field == "" && !negated {
+				terms = append(terms, n)
+			}
+		}
+	}
+	walk(q.Root, false)
+	return terms
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenPhrase
+	tokenField
+	tokenNot
+	tokenOr
+	tokenLParen
+	tokenRParen
+	tokenEOF
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	// field と op は tokenField の場合のみ設定されます
+	field string
+	op    string
+	pos   int
+}
+
+// Parse は検索クエリを解析します。構文エラーの場合は *ParseError を返します
+func Parse(input string) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, &ParseError{Pos: 0, Message: "empty query"}
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &ParseError{Pos: t.pos, Message: "unexpected " + describeToken(t)}
+	}
+
+	return &Query{Root: root}, nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Node{first}
+	for p.peek().kind == tokenOr {
+		orToken := p.next()
+		if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr {
+			return nil, &ParseError{Pos: orToken.pos, Message: "OR must be followed by a term"}
+		}
+		child, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &OrNode{Children: children}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var children []Node
+	for {
+		k := p.peek().kind
+		if k == tokenEOF || k == tokenRParen || k == tokenOr {
+			break
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 0 {
+		t := p.peek()
+		return nil, &ParseError{Pos: t.pos, Message: "expected a term before " + describeToken(t)}
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &AndNode{Children: children}, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		if p.peek().kind == tokenRParen {
+			return nil, &ParseError{Pos: t.pos, Message: "empty parentheses"}
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &ParseError{Pos: t.pos, Message: "unclosed parenthesis"}
+		}
+		return inner, nil
+
+	case tokenWord:
+		return &TermNode{Value: t.value, Pos: t.pos}, nil
+
+	case tokenPhrase:
+		return &TermNode{Value: t.value, Phrase: true, Pos: t.pos}, nil
+
+	case tokenField:
+		return parseField(t)
+
+	default:
+		return nil, &ParseError{Pos: t.pos, Message: "unexpected " + describeToken(t)}
+	}
+}
+
+func parseField(t token) (Node, error) {
+	if numericFields[t.field] {
+		op := t.op
+		if op == ":" {
+			op = "="
+		}
+		n, err := strconv.Atoi(t.value)
+		if err != nil {
+			return nil, &ParseError{Pos: t.pos, Message: fmt.Sprintf("%s must be compared with a number", t.field)}
+		}
+		return &CompareNode{Field: t.field, Op: op, Value: n, Pos: t.pos}, nil
+	}
+
+	if t.op != ":" {
+		return nil, &ParseError{Pos: t.pos, Message: fmt.Sprintf("%s does not support %s", t.field, t.op)}
+	}
+	return &TermNode{Field: t.field, Value: t.value, Phrase: strings.ContainsRune(t.value, ' '), Pos: t.pos}, nil
+}
+
+func describeToken(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenRParen:
+		return `")"`
+	case tokenLParen:
+		return `"("`
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return `"-"`
+	default:
+		return strconv.Quote(t.value)
+	}
+}
+
+// tokenize はクエリを字句に分割します
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: i})
+			i++
+
+		case r == '"':
+			value, end, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenPhrase, value: value, pos: i})
+			i = end
+
+		case r == '-':
+			if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) || runes[i+1] == ')' {
+				return nil, &ParseError{Pos: i, Message: `"-" must be followed by a term`}
+			}
+			tokens = append(tokens, token{kind: tokenNot, pos: i})
+			i++
+
+		default:
+			start := i
+			for i < len(runes) && !isWordBoundary(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+
+			// name:value または name>value の形式であればフィールド条件とする
+			if i < len(runes) && isFieldOperatorStart(runes[i]) && Fields[strings.ToLower(word)] {
+				t, end, err := readField(runes, start, i, strings.ToLower(word))
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, t)
+				i = end
+				continue
+			}
+
+			// 未知のフィールド名 (URL など) は演算子も含めて1語として扱う
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			word = string(runes[start:i])
+
+			if word == "OR" {
+				tokens = append(tokens, token{kind: tokenOr, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokenWord, value: word, pos: start})
+			}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// readField はフィールド名の後ろの演算子と値を読み込みます
+func readField(runes []rune, start, i int, field string) (token, int, error) {
+	op := string(runes[i])
+	i++
+	// score:>100 のようにコロンの後に比較演算子を書くこともできる
+	if op == ":" && i < len(runes) && (runes[i] == '>' || runes[i] == '<') {
+		op = string(runes[i])
+		i++
+	}
+	if (op == ">" || op == "<") && i < len(runes) && runes[i] == '=' {
+		op += "="
+		i++
+	}
+
+	if i >= len(runes) || unicode.IsSpace(runes[i]) || runes[i] == ')' {
+		return token{}, 0, &ParseError{Pos: start, Message: fmt.Sprintf("missing value for %s", field)}
+	}
+
+	if runes[i] == '"' {
+		value, end, err := readQuoted(runes, i)
+		if err != nil {
+			return token{}, 0, err
+		}
+		return token{kind: tokenField, field: field, op: op, value: value, pos: start}, end, nil
+	}
+
+	valueStart := i
+	for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+		i++
+	}
+	return token{kind: tokenField, field: field, op: op, value: string(runes[valueStart:i]), pos: start}, i, nil
+}
+
+// readQuoted は start の位置の " から対応する " までを読み込みます。\" でエスケープできます
+func readQuoted(runes []rune, start int) (string, int, error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			}
+		case '"':
+			value := strings.TrimSpace(sb.String())
+			if value == "" {
+				return "", 0, &ParseError{Pos: start, Message: "empty quoted phrase"}
+			}
+			return value, i + 1, nil
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, &ParseError{Pos: start, Message: "unterminated quote"}
+}
+
+func isWordBoundary(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' || isFieldOperatorStart(r)
+}
+
+func isFieldOperatorStart(r rune) bool {
+	return r == ':' || r == '>' || r == '<' || r == '='
+}
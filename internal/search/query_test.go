@@ -0,0 +1,188 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// formatNode は構文木を比較しやすい文字列にします
+// 語は go、フレーズは "go lang"、フィールドは tag:go、比較は score>=100 のように表します
+func formatNode(n Node) string {
+	switch n := n.(type) {
+	case *AndNode:
+		return "AND(" + formatNodes(n.Children) + ")"
+	case *OrNode:
+		return "OR(" + formatNodes(n.Children) + ")"
+	case *NotNode:
+		return "NOT(" + formatNode(n.Child) + ")"
+	case *TermNode:
+		value := n.Value
+		if n.Phrase {
+			value = fmt.Sprintf("%q", value)
+		}
+		if n.Field != "" {
+			return n.Field + ":" + value
+		}
+		return value
+	case *CompareNode:
+		return fmt.Sprintf("%s%s%d", n.Field, n.Op, n.Value)
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func formatNodes(nodes []Node) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		parts = append(parts, formatNode(n))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"go", "go"},
+		{"  go  ", "go"},
+		{"go rust", "AND(go rust)"},
+		{"go OR rust", "OR(go rust)"},
+		{"go rust OR zig", "OR(AND(go rust) zig)"},
+		{"go or rust", "AND(go or rust)"},
+		{"-crypto", "NOT(crypto)"},
+		{"--crypto", "NOT(NOT(crypto))"},
+		{"a-b", "a-b"},
+		{"x -(a OR b)", "AND(x NOT(OR(a b)))"},
+		{"(rust OR zig) -crypto", "AND(OR(rust zig) NOT(crypto))"},
+		{`"hacker news"`, `"hacker news"`},
+		{`"  go  "`, `"go"`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{`"a\\b"`, `"a\\b"`},
+		{"tag:go", "tag:go"},
+		{"TAG:Go", "tag:Go"},
+		{`source:"Hacker News"`, `source:"Hacker News"`},
+		{`author:"pg"`, "author:pg"},
+		{`title:"hacker news"`, `title:"hacker news"`},
+		{"tag:go(x)", "AND(tag:go x)"},
+		{"score>100", "score>100"},
+		{"score>=100", "score>=100"},
+		{"score<5", "score<5"},
+		{"score<=5", "score<=5"},
+		{"score=3", "score=3"},
+		{"score:50", "score=50"},
+		{"score:>100", "score>100"},
+		{"score:>=100", "score>=100"},
+		{"score:<=-1", "score<=-1"},
+		{"https://example.com/a?b=c", "https://example.com/a?b=c"},
+		{"foo:bar", "foo:bar"},
+		{"c++ c#", "AND(c++ c#)"},
+		{"日本語 検索", "AND(日本語 検索)"},
+		{`tag:go source:"Hacker News" author:pg score>100 -crypto (rust OR zig)`,
+			`AND(tag:go source:"Hacker News" author:pg score>100 NOT(crypto) OR(rust zig))`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			query, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if got := formatNode(query.Root); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	query, err := Parse(`日本語 -"検索 エンジン" score>=10`)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	and := query.Root.(*AndNode)
+	if pos := and.Children[0].(*TermNode).Pos; pos != 0 {
+		t.Errorf("term position = %d, want 0", pos)
+	}
+	// 位置はバイトではなく文字 (rune) 単位
+	if pos := and.Children[1].(*NotNode).Child.(*TermNode).Pos; pos != 5 {
+		t.Errorf("phrase position = %d, want 5", pos)
+	}
+	if pos := and.Children[2].(*CompareNode).Pos; pos != 15 {
+		t.Errorf("compare position = %d, want 15", pos)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+		pos     int
+	}{
+		{"", "empty query", 0},
+		{"   ", "empty query", 0},
+		{"go -", `"-" must be followed by a term`, 3},
+		{"- go", `"-" must be followed by a term`, 0},
+		{"(go -)", `"-" must be followed by a term`, 4},
+		{"go OR", "OR must be followed by a term", 3},
+		{"go OR OR rust", "OR must be followed by a term", 3},
+		{"(go OR )", "OR must be followed by a term", 4},
+		{"OR go", "expected a term before OR", 0},
+		{"go (OR x)", "expected a term before OR", 4},
+		{"(go", "unclosed parenthesis", 0},
+		{"x (go (rust)", "unclosed parenthesis", 2},
+		{"()", "empty parentheses", 0},
+		{"go)", `unexpected ")"`, 2},
+		{`"go`, "unterminated quote", 0},
+		{`日本 "x`, "unterminated quote", 3},
+		{`"go\"`, "unterminated quote", 0},
+		{`""`, "empty quoted phrase", 0},
+		{`"  "`, "empty quoted phrase", 0},
+		{"tag:", "missing value for tag", 0},
+		{"x tag: go", "missing value for tag", 2},
+		{"(tag:)", "missing value for tag", 1},
+		{`tag:"`, "unterminated quote", 4},
+		{`source:""`, "empty quoted phrase", 7},
+		{"score>abc", "score must be compared with a number", 0},
+		{"x score:", "missing value for score", 2},
+		{"tag>go", "tag does not support >", 0},
+		{"author<=pg", "author does not support <=", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want *ParseError", tt.input, err)
+			}
+			if parseErr.Message != tt.message || parseErr.Pos != tt.pos {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.input, parseErr.Message, parseErr.Pos, tt.message, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParseErrorString(t *testing.T) {
+	_, err := Parse("go)")
+	if err == nil || err.Error() != `unexpected ")" at position 2` {
+		t.Errorf("Error() = %v, want %q", err, `unexpected ")" at position 2`)
+	}
+}
+
+func TestTextTerms(t *testing.T) {
+	query, err := Parse(`go "web app" tag:rust -crypto -(-zig) OR score>1`)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	var got []string
+	for _, term := range query.TextTerms() {
+		got = append(got, term.Value)
+	}
+	// フィールド条件と否定された語は含めない。二重否定は否定されていないとみなす
+	if want := "go|web app|zig"; strings.Join(got, "|") != want {
+		t.Errorf("TextTerms = %q, want %q", strings.Join(got, "|"), want)
+	}
+}
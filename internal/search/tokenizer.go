@@ -0,0 +1,181 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 日本語の文章には単語の区切りがないため、漢字・ひらがな・カタカナの連続は
+// 2文字ずつの n-gram (bigram) に分割して索引・検索します。
+// 英数字は従来通り単語単位で扱います。
+//
+// 例: "Goで作るＷｅｂアプリ" → go / で作 / 作る / web / あぷ / ぷり
+// (全角・半角の違いとカタカナ・ひらがなの違いは正規化で吸収します)
+
+const (
+	// maxTokenLength を超える語は索引しません (URL の断片など)
+	maxTokenLength = 100
+	// maxVectorTokens は1件の文書から索引する語の最大数です。tsvector のサイズ上限を超えないようにします
+	maxVectorTokens = 20000
+)
+
+// Normalize は NFKC 正規化と小文字化を行い、カタカナをひらがなに揃えます
+func Normalize(s string) string {
+	s = strings.ToLower(norm.NFKC.String(s))
+	return strings.Map(func(r rune) rune {
+		// ァ (U+30A1) 〜 ヶ (U+30F6) は 0x60 引くと対応するひらがなになる
+		if r >= 0x30A1 && r <= 0x30F6 {
+			return r - 0x60
+		}
+		return r
+	}, s)
+}
+
+// Tokenize は文字列を索引用の語に分割します
+func Tokenize(s string) []string {
+	var tokens []string
+	for _, group := range tokenGroups(s) {
+		tokens = append(tokens, group.tokens...)
+	}
+	return tokens
+}
+
+// tokenGroup は文字列中で連続する語のまとまりです
+// 英数字の単語は1語、日本語の連続は bigram の並びになります
+type tokenGroup struct {
+	tokens []string
+	cjk    bool
+}
+
+func tokenGroups(s string) []tokenGroup {
+	runes := []rune(Normalize(s))
+	var groups []tokenGroup
+
+	for i := 0; i < len(runes); {
+		switch {
+		case isCJK(runes[i]):
+			start := i
+			for i < len(runes) && isCJK(runes[i]) {
+				i++
+			}
+			groups = append(groups, tokenGroup{tokens: bigrams(runes[start:i]), cjk: true})
+
+		case isWordRune(runes[i]):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) && !isCJK(runes[i]) {
+				i++
+			}
+			word := strings.Trim(string(runes[start:i]), ".")
+			if word != "" && len(word) <= maxTokenLength {
+				groups = append(groups, tokenGroup{tokens: []string{word}})
+			}
+
+		default:
+			i++
+		}
+	}
+
+	return groups
+}
+
+func bigrams(runes []rune) []string {
+	if len(runes) == 1 {
+		return []string{string(runes)}
+	}
+	tokens := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		tokens = append(tokens, string(runes[i:i+2]))
+	}
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー' || r == '々'
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '+' || r == '#' || r == '.'
+}
+
+// WeightedText は重み (A〜D) 付きで索引する文字列です
+type WeightedText struct {
+	Text   string
+	Weight byte
+}
+
+// BuildVector は文字列を分割した語から tsvector のリテラルを作成します
+// Postgres の to_tsvector は日本語を分割できないため、分割は Go で行い CAST(? AS tsvector) で保存します
+func BuildVector(fields ...WeightedText) string {
+	var sb strings.Builder
+	position := 1
+	count := 0
+	for _, field := range fields {
+		for _, token := range Tokenize(field.Text) {
+			if count >= maxVectorTokens {
+				return sb.String()
+			}
+			if sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			// 位置の上限 16383 を超えた分は Postgres が 16383 に丸める
+			fmt.Fprintf(&sb, "%s:%d%c", quoteLexeme(token), position, field.Weight)
+			position++
+			count++
+		}
+		// フィールドをまたいでフレーズが一致しないように位置を空ける
+		position++
+	}
+	return sb.String()
+}
+
+// TermQuery は検索語を tsquery のリテラルに変換します
+// 日本語の bigram は隣接 (<->) で、単語同士は AND で結合します。英数字の単語は前方一致にします
+// weights を指定した場合はその重みのフィールド (例: "A" はタイトル) のみに一致させます
+// 検索に使える語がない場合は空文字を返します
+func TermQuery(s string, weights string) string {
+	var parts []string
+	for _, group := range tokenGroups(s) {
+		if !group.cjk || (len(group.tokens) == 1 && len([]rune(group.tokens[0])) == 1) {
+			// 英数字の単語と日本語の1文字は前方一致にする (1文字はその文字で始まる bigram に一致する)
+			parts = append(parts, quoteLexeme(group.tokens[0])+":*"+weights)
+			continue
+		}
+		parts = append(parts, joinLexemes(group.tokens, " <-> ", weights))
+	}
+	return strings.Join(parts, " & ")
+}
+
+// PhraseQuery はフレーズを tsquery のリテラルに変換します。全ての語が隣接している文書に一致します
+// weights は TermQuery と同じく、一致させるフィールドの重みです
+func PhraseQuery(s string, weights string) string {
+	tokens := Tokenize(s)
+	if len(tokens) <= 1 {
+		return TermQuery(s, weights)
+	}
+	return joinLexemes(tokens, " <-> ", weights)
+}
+
+func joinLexemes(tokens []string, separator string, weights string) string {
+	quoted := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		lexeme := quoteLexeme(token)
+		if weights != "" {
+			lexeme += ":" + weights
+		}
+		quoted = append(quoted, lexeme)
+	}
+	if len(quoted) > 1 {
+		return "(" + strings.Join(quoted, separator) + ")"
+	}
+	return strings.Join(quoted, separator)
+}
+
+// quoteLexeme は tsvector / tsquery のリテラル中の語をエスケープします
+func quoteLexeme(token string) string {
+	token = strings.ReplaceAll(token, `\`, `\\`)
+	token = strings.ReplaceAll(token, "'", "''")
+	return "'" + token + "'"
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ＧＯ", "go"},
+		{"Ｗｅｂ１２３", "web123"},
+		{"カタカナ", "かたかな"},
+		{"ｶﾀｶﾅ", "かたかな"},
+		{"ｶﾞｲﾄﾞ", "がいど"},
+		{"ヴァイオリン", "ゔぁいおりん"},
+		{"ヶ月", "ゖ月"},
+		{"コーヒー", "こーひー"},
+		{"㍻", "平成"},
+		{"①", "1"},
+		{"漢字とひらがな", "漢字とひらがな"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"Goで作るＷｅｂアプリ", []string{"go", "で作", "作る", "web", "あぷ", "ぷり"}},
+		{"検索エンジン", []string{"検索", "索え", "えん", "んじ", "じん"}},
+		{"日本", []string{"日本"}},
+		{"本", []string{"本"}},
+		{"東京タワー", []string{"東京", "京た", "たわ", "わー"}},
+		{"C++ と C#", []string{"c++", "と", "c#"}},
+		{"v1.2. snake_case", []string{"v1.2", "snake_case"}},
+		{"hello, world!", []string{"hello", "world"}},
+		{"!!! --- ???", nil},
+		{"", nil},
+		{strings.Repeat("a", maxTokenLength+1) + " ok", []string{"ok"}},
+	}
+
+	for _, tt := range tests {
+		if got := Tokenize(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTermQuery(t *testing.T) {
+	tests := []struct {
+		input   string
+		weights string
+		want    string
+	}{
+		{"ＧＯ", "", "'go':*"},
+		{"go web", "", "'go':* & 'web':*"},
+		{"go", "A", "'go':*A"},
+		{"本", "", "'本':*"},
+		{"日本", "", "'日本'"},
+		{"日本", "A", "'日本':A"},
+		{"検索エンジン", "", "('検索' <-> '索え' <-> 'えん' <-> 'んじ' <-> 'じん')"},
+		{"ケンサク", "", "('けん' <-> 'んさ' <-> 'さく')"},
+		{"Go言語", "A", "'go':*A & '言語':A"},
+		{"!!!", "", ""},
+		{"--- ???", "A", ""},
+	}
+
+	for _, tt := range tests {
+		if got := TermQuery(tt.input, tt.weights); got != tt.want {
+			t.Errorf("TermQuery(%q, %q) = %q, want %q", tt.input, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestPhraseQuery(t *testing.T) {
+	tests := []struct {
+		input   string
+		weights string
+		want    string
+	}{
+		{"hacker news", "", "('hacker' <-> 'news')"},
+		{"hacker news", "A", "('hacker':A <-> 'news':A)"},
+		{"Go言語 入門", "", "('go' <-> '言語' <-> '入門')"},
+		{"ＧＯ", "", "'go':*"},
+		{"go", "A", "'go':*A"},
+		{"!!!", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := PhraseQuery(tt.input, tt.weights); got != tt.want {
+			t.Errorf("PhraseQuery(%q, %q) = %q, want %q", tt.input, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestBuildVector(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []WeightedText
+		want   string
+	}{
+		{
+			name:   "fields are separated by a position gap",
+			fields: []WeightedText{{Text: "Go 入門", Weight: 'A'}, {Text: "ｶﾞｲﾄﾞ", Weight: 'B'}},
+			want:   "'go':1A '入門':2A 'がい':4B 'いど':5B",
+		},
+		{
+			name:   "full-width and katakana match the query side",
+			fields: []WeightedText{{Text: "ＧＯのケンサク", Weight: 'C'}},
+			want:   "'go':1C 'のけ':2C 'けん':3C 'んさ':4C 'さく':5C",
+		},
+		{
+			name:   "symbols only",
+			fields: []WeightedText{{Text: "!!! ???", Weight: 'A'}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildVector(tt.fields...); got != tt.want {
+				t.Errorf("BuildVector = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildVectorTokenLimit(t *testing.T) {
+	text := strings.Repeat("go ", maxVectorTokens+10)
+	if got := strings.Count(BuildVector(WeightedText{Text: text, Weight: 'D'}), "'go'"); got != maxVectorTokens {
+		t.Errorf("indexed %d tokens, want %d", got, maxVectorTokens)
+	}
+}
+
+func TestQuoteLexeme(t *testing.T) {
+	if got := quoteLexeme(`a'b\c`); got != `'a''b\\c'` {
+		t.Errorf("quoteLexeme = %q, want %q", got, `'a''b\\c'`)
+	}
+}
@@ -23,8 +23,13 @@ func (s *Server) RegisterRoutes() http.Handler {
 		{
 			user.POST("/signup", s.authHandler.SignUp)
 			user.POST("/signin", s.authHandler.SignIn)
+			user.GET("/me", s.userHandler.GetMe, authMiddleware.SessionMiddleware())
+			user.PATCH("/me", s.userHandler.UpdateMe, authMiddleware.SessionMiddleware()) // 興味の登録でオンボーディングを完了
 		}
 
+		// 興味として選べるタグの候補
+		api.GET("/interests", s.userHandler.GetInterestVocabulary, authMiddleware.SessionMiddleware())
+
 		// 記事関連
 		article := api.Group("/articles", authMiddleware.SessionMiddleware())
 		{
@@ -32,7 +37,28 @@ func (s *Server) RegisterRoutes() http.Handler {
 			article.GET("/:articleId", s.articleHandler.GetArticle)
 			article.GET("/recommended", s.articleHandler.GetRecommendedArticles)
 			article.GET("/search", s.articleHandler.SearchArticles)
-			// article.GET("/content", s.articleHandler.GetArticleContent)
+			article.GET("/:articleId/content", s.articleHandler.GetArticleContent)
+			article.GET("/:articleId/related", s.articleHandler.GetRelatedArticles) // この記事を保存した人が他に保存した記事
+			article.POST("/:articleId/view", s.interactionHandler.RecordView)
+			article.PUT("/:articleId/like", s.interactionHandler.LikeArticle)
+			article.DELETE("/:articleId/like", s.interactionHandler.UnlikeArticle)
+		}
+
+		// 記事ソース関連
+		source := api.Group("/sources", authMiddleware.SessionMiddleware())
+		{
+			source.GET("", s.articleHandler.GetSources)
+		}
+
+		// 保存した検索条件関連
+		savedSearch := api.Group("/searches", authMiddleware.SessionMiddleware())
+		{
+			savedSearch.GET("", s.searchHandler.GetSavedSearches)
+			savedSearch.POST("", s.searchHandler.CreateSavedSearch)
+			savedSearch.GET("/:id", s.searchHandler.GetSavedSearch)
+			savedSearch.PUT("/:id", s.searchHandler.UpdateSavedSearch)
+			savedSearch.DELETE("/:id", s.searchHandler.DeleteSavedSearch)
+			savedSearch.GET("/:id/matches", s.searchHandler.GetMatches) // 新しく一致した記事を取得して既読にする
 		}
 
 		// メモ関連
@@ -43,6 +69,7 @@ func (s *Server) RegisterRoutes() http.Handler {
 			memo.PUT("/:articleId", s.memoHandler.UpdateMemoHandler)    // メモを更新
 			memo.DELETE("/:articleId", s.memoHandler.DeleteMemoHandler) // メモを削除
 			memo.GET("/list", s.memoHandler.GetMemosHandler)            // メモ一覧を取得
+			memo.GET("/search", s.memoHandler.SearchMemosHandler)       // メモを検索
 		}
 	}
 
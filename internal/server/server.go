@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -16,18 +17,22 @@ import (
 	"SmartBook/internal/database"
 	"SmartBook/internal/firebase"
 	"SmartBook/internal/handler"
+	"SmartBook/internal/llm"
 	"SmartBook/internal/repository"
 	"SmartBook/internal/usecase"
 )
 
 type Server struct {
-	port           int
-	db             *gorm.DB
-	articleHandler *handler.ArticleHandler
-	memoHandler    *handler.MemoHandler
-	cache          cache.Cache
-	authHandler    *handler.AuthHandler
-	store          *sessions.CookieStore
+	port               int
+	db                 *gorm.DB
+	articleHandler     *handler.ArticleHandler
+	memoHandler        *handler.MemoHandler
+	searchHandler      *handler.SavedSearchHandler
+	interactionHandler *handler.InteractionHandler
+	cache              cache.Cache
+	authHandler        *handler.AuthHandler
+	userHandler        *handler.UserHandler
+	store              *sessions.CookieStore
 }
 
 // var store = sessions.NewCookieStore([]byte(os.Getenv("SESSION_SECRET")))
@@ -47,8 +52,43 @@ func NewServer() *http.Server {
 	// 1時間ごとに期限切れのアイテムを削除
 	go cacheInstance.StartCleanup(1 * time.Hour)
 
-	articleUseCase, _ := usecase.NewArticleUseCase(httpClient, cacheInstance)
-	articleHandler := handler.NewArticleHandler(articleUseCase)
+	articleRepository := repository.NewArticleRepository(db)
+	feedRepository := repository.NewFeedRepository(db)
+	sourceRegistry := usecase.NewDefaultSourceRegistry(context.Background(), httpClient, feedRepository)
+	// LLM が設定されていない場合や作成に失敗した場合は、LLM を使わずに推薦する
+	llmProvider, err := llm.NewProviderFromEnv(context.Background())
+	if err != nil {
+		fmt.Println("🟡 LLM provider is not available, recommendations use scoring only:", err)
+	} else {
+		fmt.Println("🟢 LLM provider:", llmProvider.Name())
+	}
+	contentRepository := repository.NewContentRepository(db)
+	userRepository := repository.NewUserRepository(db, firebaseClient)
+	contentRecommender := usecase.NewContentRecommender(articleRepository, contentRepository, userRepository, cacheInstance)
+	collaborativeRepository := repository.NewCollaborativeRepository(db)
+	collaborativeUseCase := usecase.NewCollaborativeUseCase(collaborativeRepository, articleRepository, userRepository)
+	articleUseCase := usecase.NewArticleUseCase(httpClient, cacheInstance, articleRepository, sourceRegistry, llmProvider, contentRecommender, collaborativeUseCase)
+	contentUseCase := usecase.NewContentUseCase(sourceRegistry, articleRepository, contentRepository)
+	userUseCase := usecase.NewUserUseCase(userRepository, articleRepository)
+	userHandler := handler.NewUserHandler(userUseCase)
+	articleHandler := handler.NewArticleHandler(articleUseCase, contentUseCase, userUseCase)
+
+	interactionRepository := repository.NewInteractionRepository(db)
+	interactionUseCase := usecase.NewInteractionUseCase(interactionRepository, articleRepository)
+	interactionHandler := handler.NewInteractionHandler(interactionUseCase)
+
+	savedSearchRepository := repository.NewSavedSearchRepository(db)
+	savedSearchUseCase := usecase.NewSavedSearchUseCase(savedSearchRepository, articleRepository)
+	searchHandler := handler.NewSavedSearchHandler(savedSearchUseCase)
+	// 取り込んだ新しい記事を保存した検索条件と照合する
+	articleUseCase.OnNewArticles(savedSearchUseCase.EvaluateNewArticles)
+
+	// 記事ソースごとのバックグラウンド取り込みを開始
+	articleUseCase.StartIngestion(context.Background())
+
+	// 保存された記事同士の類似度の定期的な計算を開始
+	collaborativeUseCase.Start(context.Background())
+
 	memoUseCase := usecase.NewMemoUseCase(db)
 	memoHandler := handler.NewMemoHandler(memoUseCase)
 	authRepository, _ := repository.NewAuthRepository(db, firebaseClient)
@@ -56,12 +96,15 @@ func NewServer() *http.Server {
 	authHandler := handler.NewAuthHandler(authUseCase)
 
 	newServer := &Server{
-		port:           port,
-		db:             db,
-		articleHandler: articleHandler,
-		memoHandler:    memoHandler,
-		cache:          cacheInstance,
-		authHandler:    authHandler,
+		port:               port,
+		db:                 db,
+		articleHandler:     articleHandler,
+		memoHandler:        memoHandler,
+		searchHandler:      searchHandler,
+		interactionHandler: interactionHandler,
+		cache:              cacheInstance,
+		authHandler:        authHandler,
+		userHandler:        userHandler,
 	}
 
 	// Declare Server config
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"sort"
+	"strings"
+)
+
+// mergeDuplicateArticles は正規化したURLが同じ記事を1件にまとめます
+// まとめた記事はスコアの最も高い記事のIDとタイトルを使い、全てのソースと合計スコアを持ちます
+// 作成日時は最も新しい記事のものを使います。新着の一覧 (FindArticleGroups) の並び順とカーソルに合わせるためです
+func mergeDuplicateArticles(articles []model.Article) []model.Article {
+	merged := make([]model.Article, 0, len(articles))
+	indexByURL := make(map[string]int, len(articles))
+	// まとめる前のスコアの最大値。代表とする記事の判定に使う
+	bestScores := make(map[string]int, len(articles))
+
+	for _, article := range articles {
+		key := repository.CanonicalizeURL(article.URL)
+		if key == "" {
+			// URL を持たない記事 (Ask HN など) はまとめない
+			merged = append(merged, withSources(article))
+			continue
+		}
+
+		i, found := indexByURL[key]
+		if !found {
+			indexByURL[key] = len(merged)
+			bestScores[key] = article.Score
+			merged = append(merged, withSources(article))
+			continue
+		}
+
+		current := merged[i]
+		combined := current
+		if article.Score > bestScores[key] {
+			// スコアの高い記事を代表とする
+			combined.ID = article.ID
+			combined.Title = article.Title
+			combined.URL = article.URL
+			combined.Author = article.Author
+			combined.Source = article.Source
+			bestScores[key] = article.Score
+		}
+		combined.Score = current.Score + article.Score
+		if article.CreatedAt.After(current.CreatedAt) {
+			combined.CreatedAt = article.CreatedAt
+		}
+		combined.Sources = appendUnique(current.Sources, article.Source)
+		combined.Tags = appendUniqueFold(current.Tags, article.Tags...)
+		combined.DuplicateIDs = appendUnique(appendUnique(current.DuplicateIDs, current.ID), article.ID)
+		combined.DuplicateIDs = removeString(combined.DuplicateIDs, combined.ID)
+		merged[i] = combined
+	}
+
+	for i := range merged {
+		sort.Strings(merged[i].DuplicateIDs)
+	}
+	return merged
+}
+
+func withSources(article model.Article) model.Article {
+	if len(article.Sources) == 0 && article.Source != "" {
+		article.Sources = []string{article.Source}
+	}
+	return article
+}
+
+func appendUnique(values []string, items ...string) []string {
+	result := append([]string{}, values...)
+	for _, item := range items {
+		found := false
+		for _, v := range result {
+			if v == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// appendUniqueFold は大文字小文字を区別せずに重複を除いて追加します
+func appendUniqueFold(values []string, items ...string) []string {
+	result := append([]string{}, values...)
+	for _, item := range items {
+		found := false
+		for _, v := range result {
+			if strings.EqualFold(v, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func removeString(values []string, target string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != target {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// matchesArticleID は記事自身のIDか、まとめられた記事のIDに一致するかを判定します
+func matchesArticleID(article model.Article, id string) bool {
+	if article.ID == id {
+		return true
+	}
+	for _, duplicateID := range article.DuplicateIDs {
+		if duplicateID == id {
+			return true
+		}
+	}
+	return false
+}
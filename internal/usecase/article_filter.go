@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultArticleLimit は limit が指定されなかった場合の件数です
+	defaultArticleLimit = 30
+	// maxArticleLimit は1回に返す記事の最大件数です
+	maxArticleLimit = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// normalizeArticleLimit は limit を 1 から maxArticleLimit の範囲に収めます
+func normalizeArticleLimit(limit int) int {
+	if limit <= 0 {
+		return defaultArticleLimit
+	}
+	if limit > maxArticleLimit {
+		return maxArticleLimit
+	}
+	return limit
+}
+
+// encodeArticleCursor は記事の作成日時と ID から次のページのカーソルを作成します
+func encodeArticleCursor(article model.Article) string {
+	raw := strconv.FormatInt(article.CreatedAt.UnixNano(), 10) + "|" + article.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeArticleCursor はカーソルを復元します。空文字の場合は nil を返します
+func decodeArticleCursor(cursor string) (*model.ArticleCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &model.ArticleCursor{CreatedAt: time.Unix(0, n), ID: id}, nil
+}
+
+// encodeOffsetCursor は関連度順など、作成日時順ではない結果のページ位置をカーソルにします
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset|" + strconv.Itoa(offset)))
+}
+
+// decodeOffsetCursor はカーソルからページ位置を復元します。空文字の場合は 0 を返します
+func decodeOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	kind, value, found := strings.Cut(string(raw), "|")
+	if !found || kind != "offset" {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
+
+// matchesArticleFilter は記事が絞り込み条件を満たすかを判定します
+// DBを使わずに絞り込む場合 (おすすめ記事など) に使います
+func matchesArticleFilter(article model.Article, filter model.ArticleFilter) bool {
+	if len(filter.Sources) > 0 {
+		sources := article.Sources
+		if len(sources) == 0 {
+			sources = []string{article.Source}
+		}
+		if !containsAnyFold(sources, filter.Sources) {
+			return false
+		}
+	}
+	if len(filter.Tags) > 0 && !containsAnyFold(article.Tags, filter.Tags) {
+		return false
+	}
+	if filter.Since != nil && article.CreatedAt.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && !article.CreatedAt.Before(*filter.Until) {
+		return false
+	}
+	if filter.MinScore != nil && article.Score < *filter.MinScore {
+		return false
+	}
+	return true
+}
+
+func containsAnyFold(values []string, targets []string) bool {
+	for _, v := range values {
+		for _, t := range targets {
+			if strings.EqualFold(v, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,118 +1,107 @@
 package usecase
 
 import (
+	"SmartBook/internal/llm"
 	"SmartBook/internal/model"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
-	"regexp"
 	"sort"
 	"strings"
-
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
 )
 
-// GeminiClient は Gemini AI との通信を担当します
-type GeminiClient struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
-}
+// 推薦の方法です。RECOMMENDER_STRATEGY で選びます
+const (
+	// RecommenderStrategyAI は LLM で候補を並べ替えます (既定)
+	RecommenderStrategyAI = "ai"
+	// RecommenderStrategyTFIDF は TF-IDF のコサイン類似度で推薦します
+	RecommenderStrategyTFIDF = "tfidf"
+	// RecommenderStrategyCollaborative は他のユーザーのメモといいねからアイテム間の協調フィルタリングで推薦します
+	RecommenderStrategyCollaborative = "collaborative"
+)
 
-// NewGeminiClient は新しい GeminiClient インスタンスを作成します
-func NewGeminiClient() (*GeminiClient, error) {
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+// recommenderStrategyFromEnv は RECOMMENDER_STRATEGY から推薦の方法を読み込みます
+func recommenderStrategyFromEnv() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("RECOMMENDER_STRATEGY")))
+	switch value {
+	case "":
+		return RecommenderStrategyAI
+	case RecommenderStrategyAI, RecommenderStrategyTFIDF, RecommenderStrategyCollaborative:
+		return value
 	}
-
-	model := client.GenerativeModel("gemini-pro")
-	return &GeminiClient{client: client, model: model}, nil
+	fmt.Printf("🟡 invalid RECOMMENDER_STRATEGY=%q, using %s\n", value, RecommenderStrategyAI)
+	return RecommenderStrategyAI
 }
 
-func (u *ArticleUseCase) GetRecommendedArticles(ctx context.Context, user *model.User) ([]model.Article, error) {
+// GetRecommendedArticles はユーザーへのおすすめ記事を、推薦した理由とともに返します
+// filter の絞り込み条件は推薦の候補に適用し、件数は filter.Limit に従います (ページングはしません)
+func (u *ArticleUseCase) GetRecommendedArticles(ctx context.Context, user *model.User, filter model.ArticleFilter) ([]model.RecommendedArticle, error) {
 	allArticles, err := u.GetAllArticles(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// ユーザーの行動履歴を文字列化
-	userBehavior := formatUserBehavior(user)
-
-	// Gemini AIを使用して推薦を生成
-	recommendations, err := u.getAIRecommendations(ctx, userBehavior, allArticles)
-	if err != nil {
-		// AIが失敗した場合は従来の方法にフォールバック
-		fmt.Println("🟡 AI Recommendaion error:", err)
-		return u.fallbackRecommendation(user, allArticles), nil
+	candidates := make([]model.Article, 0, len(allArticles))
+	for _, article := range allArticles {
+		if matchesArticleFilter(article, filter) {
+			candidates = append(candidates, article)
+		}
 	}
-
-	return recommendations, nil
-}
-
-func (u *ArticleUseCase) getAIRecommendations(ctx context.Context, userBehavior string, articles []model.Article) ([]model.Article, error) {
-	prompt := fmt.Sprintf(`Given the following user behavior: %s, And the following list of articles: %s, Recommend the top 30 articles for this user. Return the recommendations as a JSON array of article IDs.
-Example output format: ["article_id_1", "article_id_2", "article_id_3", ...]`, userBehavior, formatArticlesForAI(articles))
-	fmt.Println(userBehavior)
-
-	response, err := u.geminiClient.model.GenerateContent(ctx, genai.Text(prompt))
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate AI recommendations: %w", err)
+	limit := normalizeArticleLimit(filter.Limit)
+	explainer := newRecommendationExplainer(user, allArticles, candidates)
+
+	// 従来のスコアで並べておき、LLM に渡す候補の事前絞り込みとフォールバックの両方に使う
+	scored := u.scoreArticles(user, candidates)
+
+	// 設定された方法 (LLM・TF-IDF・協調フィルタリング) で推薦を生成
+	// excluded は推薦の方法が候補から除いた記事 (既に保存・閲覧した記事) で、補う記事からも除く
+	var recommendations []rankedArticle
+	var excluded map[string]bool
+	switch u.recommenderStrategy {
+	case RecommenderStrategyTFIDF:
+		recommendations, excluded, err = u.contentRecommender.Recommend(ctx, user, candidates, limit)
+	case RecommenderStrategyCollaborative:
+		recommendations, excluded, err = u.collaborativeUseCase.Recommend(ctx, user, candidates, limit)
+	default:
+		recommendations, err = u.getAIRecommendations(ctx, user, scored, limit)
 	}
-
-	if len(response.Candidates) == 0 || response.Candidates[0].Content == nil {
-		return nil, fmt.Errorf("no content generated by AI")
+	if err != nil {
+		// 失敗した場合は従来の方法にフォールバック
+		fmt.Printf("🟡 %s recommendation error: %s\n", u.recommenderStrategy, err)
+		return explainer.explainRanked(u.fallbackRecommendation(scored, limit)), nil
 	}
 
-	// AIの応答全体をログに記録
-	// fmt.Printf("AI Response: %+v\n", response.Candidates[0].Content)
+	// 有効な記事が足りない場合は、従来のスコアの順で補う
+	recommendations = backfillRecommendations(recommendations, scored, excluded, limit)
+	return explainer.explainRanked(recommendations), nil
+}
 
-	var recommendedIDs []string
-	for _, part := range response.Candidates[0].Content.Parts {
-		if text, ok := part.(genai.Text); ok {
-			// JSON配列としてパースを試みる
-			if err := json.Unmarshal([]byte(text), &recommendedIDs); err == nil {
-				break
-			}
-			// JSONパースに失敗した場合、テキストから直接IDを抽出
-			ids := extractArticleIDs(string(text))
-			if len(ids) > 0 {
-				recommendedIDs = ids
-				break
-			}
-		}
+// getAIRecommendations は従来のスコアの上位の候補を LLM で並べ替えます
+// articles は従来のスコアの高い順に並んでいる必要があります
+func (u *ArticleUseCase) getAIRecommendations(ctx context.Context, user *model.User, articles []model.Article, limit int) ([]rankedArticle, error) {
+	if u.llmProvider == nil {
+		return nil, llm.ErrNotConfigured
 	}
 
-	if len(recommendedIDs) == 0 {
-		return nil, fmt.Errorf("failed to parse AI recommendations")
+	if len(articles) > aiCandidateLimit {
+		articles = articles[:aiCandidateLimit]
 	}
+	userBehavior := llm.TruncateToTokens(formatUserBehavior(user), u.promptTokenBudget/4)
+	// プロンプトの予算が小さい場合は、並べ替えられる件数までスコアの上位に絞り込む
+	articles = rankableCandidates(userBehavior, articles, limit, u.promptTokenBudget)
 
-	// 推奨されたIDをログに記録
-	// fmt.Printf("Recommended IDs: %v\n", recommendedIDs)
-
-	return filterArticlesByIDs(articles, recommendedIDs), nil
+	return u.rankWithLLM(ctx, userBehavior, articles, limit)
 }
 
-// 文字列からアーティクルIDを抽出する補助関数
-func extractArticleIDs(text string) []string {
-	// 簡単な正規表現を使用してIDを抽出
-	re := regexp.MustCompile(`\b[a-zA-Z0-9_]+_\d+\b`)
-	return re.FindAllString(text, -1)
-}
-
-func (u *ArticleUseCase) fallbackRecommendation(user *model.User, articles []model.Article) []model.Article {
-	fmt.Println("🟡 AI recommendation failed, falling back to traditional recommendation method")
-	// 従来のスコアリング方法を使用
+// scoreArticles は記事のスコア・ソースの重み・興味との一致から計算した従来のスコアの高い順に記事を並べます
+func (u *ArticleUseCase) scoreArticles(user *model.User, articles []model.Article) []model.Article {
 	scoredArticles := make([]struct {
 		Article model.Article
 		Score   float64
 	}, len(articles))
 
 	for i, article := range articles {
-		score := float64(article.Score)
+		score := float64(article.Score) * u.sourceRegistry.Weight(article.Source)
 		for _, interest := range user.Interests {
 			if strings.Contains(strings.ToLower(article.Title), strings.ToLower(interest)) {
 				score += 100
@@ -129,16 +118,24 @@ func (u *ArticleUseCase) fallbackRecommendation(user *model.User, articles []mod
 		}{Article: article, Score: score}
 	}
 
-	sort.Slice(scoredArticles, func(i, j int) bool {
+	// 同じスコアの記事は元の順 (新しい順) を保つ
+	sort.SliceStable(scoredArticles, func(i, j int) bool {
 		return scoredArticles[i].Score > scoredArticles[j].Score
 	})
 
-	recommendedArticles := make([]model.Article, 0, 30)
-	for i := 0; i < 30 && i < len(scoredArticles); i++ {
-		recommendedArticles = append(recommendedArticles, scoredArticles[i].Article)
+	sorted := make([]model.Article, 0, len(scoredArticles))
+	for _, scored := range scoredArticles {
+		sorted = append(sorted, scored.Article)
 	}
+	return sorted
+}
 
-	return recommendedArticles
+func (u *ArticleUseCase) fallbackRecommendation(scored []model.Article, limit int) []rankedArticle {
+	fmt.Println("🟡 recommendation failed, falling back to traditional recommendation method")
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return unrankedArticles(scored)
 }
 
 func formatUserBehavior(user *model.User) string {
@@ -146,30 +143,31 @@ func formatUserBehavior(user *model.User) string {
 	// 例: 最近閲覧した記事、いいねした記事、よく読むトピックなど
 	return fmt.Sprintf("User interests: %s, Recent views: %s, Likes: %s",
 		strings.Join(user.Interests, ", "),
-		strings.Join(user.RecentViews, ", "),
-		strings.Join(user.Likes, ", "),
+		strings.Join(headStrings(user.RecentViews, maxPromptHistory), ", "),
+		strings.Join(headStrings(user.Likes, maxPromptHistory), ", "),
 	)
 }
 
 func formatArticlesForAI(articles []model.Article) string {
 	var sb strings.Builder
 	for _, article := range articles {
-		sb.WriteString(fmt.Sprintf("ID: %s, Title: %s, Tags: %s\n", article.ID, article.Title, strings.Join(article.Tags, ", ")))
+		sb.WriteString(formatArticleForAI(article))
 	}
 	return sb.String()
 }
 
-func filterArticlesByIDs(articles []model.Article, ids []string) []model.Article {
-	idMap := make(map[string]bool)
-	for _, id := range ids {
-		idMap[id] = true
+// formatArticleForAI は記事1件をプロンプトの1行にします。長いタイトルと多すぎるタグは切り詰めます
+func formatArticleForAI(article model.Article) string {
+	title := article.Title
+	if runes := []rune(title); len(runes) > maxPromptTitleRunes {
+		title = string(runes[:maxPromptTitleRunes]) + "…"
 	}
+	return fmt.Sprintf("ID: %s, Title: %s, Tags: %s\n", article.ID, title, strings.Join(headStrings(article.Tags, maxPromptTags), ", "))
+}
 
-	var filtered []model.Article
-	for _, article := range articles {
-		if idMap[article.ID] {
-			filtered = append(filtered, article)
-		}
+func headStrings(values []string, n int) []string {
+	if len(values) > n {
+		return values[:n]
 	}
-	return filtered
+	return values
 }
@@ -1,23 +1,19 @@
 package usecase
 
 import (
+	"SmartBook/internal/llm"
 	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"SmartBook/internal/search"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sort"
-	"strings"
 	"sync"
 	"time"
-
-	"golang.org/x/sync/errgroup"
 )
 
-type HackerNewsFetcher struct {
-	client *http.Client
-}
-
 type DevToFetcher struct {
 	client *http.Client
 }
@@ -29,164 +25,243 @@ type ArticleFetcher interface {
 type Cache interface {
 	Get(key string) (interface{}, bool)
 	Set(key string, value interface{}, expiration time.Duration)
+	Delete(key string)
 }
 
+// sourceArticlesLimit はソースごとに読み込む記事の最大件数です
+const sourceArticlesLimit = 300
+
 type ArticleUseCase struct {
 	client            *http.Client
-	hackerNewsFetcher ArticleFetcher
-	devToFetcher      ArticleFetcher
+	sourceRegistry    *SourceRegistry
 	cache             Cache
-	geminiClient      *GeminiClient
+	articleRepository repository.IArticleRepository
+	ingestionUseCase  *IngestionUseCase
+	// llmProvider は推薦に使う LLM です。nil の場合は LLM を使わずにスコアで推薦します
+	llmProvider llm.LLMProvider
+	// promptTokenBudget は推薦のプロンプト1回あたりのトークン数の上限です
+	promptTokenBudget int
+	// recommenderStrategy は推薦の方法 (RecommenderStrategyAI / RecommenderStrategyTFIDF) です
+	recommenderStrategy  string
+	contentRecommender   *ContentRecommender
+	collaborativeUseCase *CollaborativeUseCase
 }
 
-func NewArticleUseCase(client *http.Client, cache Cache) (*ArticleUseCase, error) {
+func NewArticleUseCase(client *http.Client, cache Cache, articleRepository repository.IArticleRepository, sourceRegistry *SourceRegistry, llmProvider llm.LLMProvider, contentRecommender *ContentRecommender, collaborativeUseCase *CollaborativeUseCase) *ArticleUseCase {
 	if client == nil {
 		client = &http.Client{Timeout: 10 * time.Second}
 	}
 
-	geminiClient, err := NewGeminiClient()
-	if err != nil {
-		fmt.Println("🔴failed to create Gemini client: %w", err)
-		return nil, err
+	return &ArticleUseCase{
+		client:               client,
+		sourceRegistry:       sourceRegistry,
+		cache:                cache,
+		articleRepository:    articleRepository,
+		ingestionUseCase:     NewIngestionUseCase(sourceRegistry.EnabledSources(), articleRepository, cache),
+		llmProvider:          llmProvider,
+		promptTokenBudget:    promptTokenBudgetFromEnv(),
+		recommenderStrategy:  recommenderStrategyFromEnv(),
+		contentRecommender:   contentRecommender,
+		collaborativeUseCase: collaborativeUseCase,
 	}
+}
 
-	return &ArticleUseCase{
-		client:            client,
-		hackerNewsFetcher: &HackerNewsFetcher{client: client},
-		devToFetcher:      &DevToFetcher{client: client},
-		cache:             cache,
-		geminiClient:      geminiClient,
-	}, nil
+// OnNewArticles は取り込みで新しい記事が保存された時に呼び出す関数を登録します。StartIngestion の前に呼び出してください
+func (u *ArticleUseCase) OnNewArticles(handler func(ctx context.Context, articleIDs []string)) {
+	u.ingestionUseCase.OnNewArticles(handler)
+}
+
+// GetRelatedArticles は記事を保存 (メモ・いいね) したユーザーが他に保存した記事を返します
+func (u *ArticleUseCase) GetRelatedArticles(ctx context.Context, articleID string, limit int) ([]model.RelatedArticle, error) {
+	return u.collaborativeUseCase.GetRelatedArticles(ctx, articleID, limit)
 }
 
+// StartIngestion は記事ソースごとのバックグラウンド取り込みを開始します
+func (u *ArticleUseCase) StartIngestion(ctx context.Context) {
+	u.ingestionUseCase.Start(ctx)
+}
+
+// GetAllArticles は取り込み済みの記事を新しい順に取得します
 func (u *ArticleUseCase) GetAllArticles(ctx context.Context) ([]model.Article, error) {
-	if cachedArticles, found := u.cache.Get("all_articles"); found {
+	result, err := u.GetAllArticlesBySource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.Articles, nil
+}
+
+// GetAllArticlesBySource はソースごとに取り込み済みの記事を読み込み、新しい順にまとめて返します
+// 記事の取得は IngestionUseCase がバックグラウンドで行うため、リクエスト中に外部APIは呼び出しません。
+// 取り込みに失敗しているソースは最後に取り込めた記事を返しつつ、FailedSources で報告します
+func (u *ArticleUseCase) GetAllArticlesBySource(ctx context.Context) (*model.ArticleListResponse, error) {
+	var result model.ArticleListResponse
+	if cachedResult, found := u.cache.Get("all_articles"); found {
 		fmt.Println("🟢 Cache hit: all_articles")
-		return cachedArticles.([]model.Article), nil
+		result = cachedResult.(model.ArticleListResponse)
+	} else {
+		loaded, err := u.loadArticlesBySource(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result = loaded
+		u.cache.Set("all_articles", result, 5*time.Minute)
 	}
 
-	var articles []model.Article
-	var mu sync.Mutex
-	g, ctx := errgroup.WithContext(ctx)
+	return &model.ArticleListResponse{
+		Articles:      result.Articles,
+		FailedSources: u.failedSources(result.FailedSources),
+	}, nil
+}
 
-	fetchAndAppend := func(fetcher ArticleFetcher, limit int) func() error {
-		return func() error {
-			fetchedArticles, err := fetcher.FetchArticles(ctx, limit)
-			if err != nil {
-				return fmt.Errorf("failed to fetch articles: %w", err)
-			}
-			mu.Lock()
-			articles = append(articles, fetchedArticles...)
-			mu.Unlock()
-			return nil
+// failedSources は読み込みに失敗したソースに、取り込みに失敗しているソースを加えて返します
+// 取り込みの失敗状況はキャッシュせず、その時点の状態を返す
+func (u *ArticleUseCase) failedSources(loadFailures []model.SourceFailure) []model.SourceFailure {
+	failedSources := append([]model.SourceFailure{}, loadFailures...)
+	for _, status := range u.ingestionUseCase.Statuses() {
+		if status.LastError == "" || containsSourceFailure(failedSources, status.Name) {
+			continue
 		}
+		failedSources = append(failedSources, model.SourceFailure{
+			Name:          status.Name,
+			Error:         status.LastError,
+			LastSuccessAt: status.LastSuccessAt,
+		})
 	}
+	return failedSources
+}
 
-	g.Go(fetchAndAppend(u.hackerNewsFetcher, 100))
-	g.Go(fetchAndAppend(u.devToFetcher, 100))
+func (u *ArticleUseCase) loadArticlesBySource(ctx context.Context) (model.ArticleListResponse, error) {
+	sources := u.sourceRegistry.EnabledSources()
 
-	if err := g.Wait(); err != nil {
-		return nil, err
+	type sourceResult struct {
+		name     string
+		articles []model.Article
+		err      error
+	}
+	results := make([]sourceResult, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			articles, err := u.articleRepository.GetRecentArticlesBySource(ctx, name, sourceArticlesLimit)
+			results[i] = sourceResult{name: name, articles: articles, err: err}
+		}(i, source.Name)
 	}
+	wg.Wait()
 
-	sort.Slice(articles, func(i, j int) bool {
-		return articles[i].CreatedAt.After(articles[j].CreatedAt)
+	result := model.ArticleListResponse{
+		Articles:      []model.Article{},
+		FailedSources: []model.SourceFailure{},
+	}
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Printf("🔴 failed to load articles: %s: %s\n", r.name, r.err)
+			result.FailedSources = append(result.FailedSources, model.SourceFailure{Name: r.name, Error: r.err.Error()})
+			continue
+		}
+		result.Articles = append(result.Articles, r.articles...)
+	}
+
+	// 全てのソースで失敗した場合のみエラーとする
+	if len(sources) > 0 && len(result.FailedSources) == len(sources) {
+		return model.ArticleListResponse{}, fmt.Errorf("failed to load articles from all sources")
+	}
+
+	// 複数のソースに掲載された同じ記事を1件にまとめる
+	result.Articles = mergeDuplicateArticles(result.Articles)
+
+	sort.Slice(result.Articles, func(i, j int) bool {
+		return result.Articles[i].CreatedAt.After(result.Articles[j].CreatedAt)
 	})
 
-	u.cache.Set("all_articles", articles, 5*time.Minute)
-	return articles, nil
+	return result, nil
 }
 
-func (u *ArticleUseCase) GetLatestArticles(ctx context.Context) ([]model.Article, error) {
-	allArticles, err := u.GetAllArticles(ctx)
-	if err != nil {
-		return nil, err
+func containsSourceFailure(failures []model.SourceFailure, name string) bool {
+	for _, failure := range failures {
+		if failure.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	if len(allArticles) > 30 {
-		return allArticles[:30], nil
+// GetSources は登録されている記事ソースと取り込み状況を返します
+func (u *ArticleUseCase) GetSources() []model.SourceInfo {
+	sources := u.sourceRegistry.Sources()
+	infos := make([]model.SourceInfo, 0, len(sources))
+	for _, source := range sources {
+		infos = append(infos, sourceInfo(source, u.ingestionUseCase.Status(source.Name)))
 	}
-	return allArticles, nil
+	return infos
 }
 
-func (u *ArticleUseCase) GetArticleByID(ctx context.Context, id string) (*model.Article, error) {
-	articles, err := u.GetAllArticles(ctx)
+// GetLatestArticles は絞り込み条件に一致する記事を新しい順に1ページ分返します
+// 複数のソースに掲載された同じ記事はページングの前に1件にまとめるため、ページをまたいで重複することはありません
+func (u *ArticleUseCase) GetLatestArticles(ctx context.Context, filter model.ArticleFilter) (*model.ArticleListResponse, error) {
+	cursor, err := decodeArticleCursor(filter.Cursor)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, article := range articles {
-		if article.ID == id {
-			return &article, nil
-		}
+	limit := normalizeArticleLimit(filter.Limit)
+	if len(filter.Sources) == 0 {
+		filter.Sources = u.enabledSourceNames()
 	}
 
-	return nil, fmt.Errorf("article not found: %s", id)
-}
-
-func (f *HackerNewsFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Article, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://hacker-news.firebaseio.com/v0/topstories.json", nil)
+	groups, err := u.articleRepository.FindArticleGroups(ctx, filter, cursor, limit+1)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to load articles: %w", err)
 	}
 
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch top stories: %w", err)
+	nextCursor := ""
+	if len(groups) > limit {
+		groups = groups[:limit]
+		// まとめた記事のIDはスコアの最も高い記事のものになるため、まとまりの最も新しい記事の位置をカーソルにする
+		nextCursor = encodeArticleCursor(groups[len(groups)-1][0])
 	}
-	defer resp.Body.Close()
 
-	var ids []int
-	if err := json.NewDecoder(resp.Body).Decode(&ids); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	articles := make([]model.Article, 0, len(groups))
+	for _, group := range groups {
+		articles = append(articles, mergeDuplicateArticles(group)...)
 	}
 
-	articles := make([]model.Article, 0, limit)
-	for i := 0; i < limit && i < len(ids); i++ {
-		article, err := f.getHackerNewsArticleByID(ctx, ids[i])
-		if err != nil {
-			continue
-		}
-		articles = append(articles, article)
-	}
+	return &model.ArticleListResponse{
+		Articles:      articles,
+		FailedSources: u.failedSources(nil),
+		NextCursor:    nextCursor,
+	}, nil
+}
 
-	return articles, nil
+func (u *ArticleUseCase) enabledSourceNames() []string {
+	sources := u.sourceRegistry.EnabledSources()
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	return names
 }
 
-func (f *HackerNewsFetcher) getHackerNewsArticleByID(ctx context.Context, id int) (model.Article, error) {
-	url := fmt.Sprintf("https://hacker-news.firebaseio.com/v0/item/%d.json", id)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+func (u *ArticleUseCase) GetArticleByID(ctx context.Context, id string) (*model.Article, error) {
+	articles, err := u.GetAllArticles(ctx)
 	if err != nil {
-		return model.Article{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	resp, err := f.client.Do(req)
+	for _, article := range articles {
+		if matchesArticleID(article, id) {
+			return &article, nil
+		}
+	}
+
+	// キャッシュにない古い記事はDBから取得する
+	article, err := u.articleRepository.GetArticleByID(ctx, id)
 	if err != nil {
-		return model.Article{}, fmt.Errorf("failed to fetch article: %w", err)
+		return nil, fmt.Errorf("article not found: %s: %w", id, err)
 	}
-	defer resp.Body.Close()
 
-	var articleData struct {
-		ID    int    `json:"id"`
-		Title string `json:"title"`
-		URL   string `json:"url"`
-		Score int    `json:"score"`
-		By    string `json:"by"`
-		Time  int64  `json:"time"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&articleData); err != nil {
-		return model.Article{}, fmt.Errorf("failed to decode article: %w", err)
-	}
-
-	return model.Article{
-		ID:        fmt.Sprintf("hn_%d", articleData.ID),
-		Title:     articleData.Title,
-		URL:       articleData.URL,
-		Score:     articleData.Score,
-		Author:    articleData.By,
-		CreatedAt: time.Unix(articleData.Time, 0),
-		Source:    "Hacker News",
-	}, nil
+	return &article, nil
 }
 
 func (f *DevToFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Article, error) {
@@ -237,31 +312,61 @@ func (f *DevToFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Ar
 	return articles, nil
 }
 
-func (u *ArticleUseCase) SearchArticles(ctx context.Context, query string) ([]model.Article, error) {
-	allArticles, err := u.GetAllArticles(ctx)
+// searchFacetTagLimit は検索結果のタグ別件数で返すタグの数です
+const searchFacetTagLimit = 20
+
+// snippetLength は検索結果の本文のスニペットの文字数です
+const snippetLength = 160
+
+// SearchArticles はクエリに一致する記事を関連度の高い順に1ページ分返します
+// 一致した件数の合計、ソース別・タグ別の件数、検索語をハイライトしたスニペットも返します
+// クエリの構文が正しくない場合は *search.ParseError を返します
+func (u *ArticleUseCase) SearchArticles(ctx context.Context, query string, filter model.ArticleFilter) (*model.SearchResponse, error) {
+	parsed, err := search.Parse(query)
 	if err != nil {
 		return nil, err
 	}
 
-	var searchResults []model.Article
-	queryLower := strings.ToLower(query)
+	offset, err := decodeOffsetCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := normalizeArticleLimit(filter.Limit)
 
-	for _, article := range allArticles {
-		if strings.Contains(strings.ToLower(article.Title), queryLower) ||
-			strings.Contains(strings.ToLower(article.Author), queryLower) ||
-			containsTag(article.Tags, queryLower) {
-			searchResults = append(searchResults, article)
-		}
+	hits, err := u.articleRepository.SearchArticles(ctx, parsed, filter, offset, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	total, err := u.articleRepository.CountSearchArticles(ctx, parsed, filter)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := u.articleRepository.SearchFacets(ctx, parsed, filter, searchFacetTagLimit)
+	if err != nil {
+		return nil, err
 	}
 
-	return searchResults, nil
-}
+	response := &model.SearchResponse{
+		Articles: make([]model.SearchResult, 0, len(hits)),
+		Total:    total,
+		Facets:   facets,
+	}
+	if len(hits) > limit {
+		hits = hits[:limit]
+		response.NextCursor = encodeOffsetCursor(offset + limit)
+	}
 
-func containsTag(tags []string, query string) bool {
-	for _, tag := range tags {
-		if strings.Contains(strings.ToLower(tag), query) {
-			return true
+	terms := parsed.HighlightTerms()
+	for _, hit := range hits {
+		result := model.SearchResult{Article: hit.Article}
+		if len(terms) > 0 {
+			result.Highlights = model.SearchHighlights{
+				Title: search.Highlight(hit.Article.Title, terms, 0),
+				Body:  search.Highlight(hit.Body, terms, snippetLength),
+			}
 		}
+		response.Articles = append(response.Articles, result)
 	}
-	return false
+
+	return response, nil
 }
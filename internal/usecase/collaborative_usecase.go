@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultCollaborativeInterval は記事同士の類似度を作り直す間隔の既定値です
+	defaultCollaborativeInterval = time.Hour
+	// maxSimilarArticles は記事ごとに保存する似ている記事の数です
+	maxSimilarArticles = 20
+	// defaultRelatedLimit と maxRelatedLimit は関連記事の件数の既定値と上限です
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+// errNoCollaborativeSignal はユーザーが保存した記事に似ている記事がなく、協調フィルタリングで推薦できない場合のエラーです
+var errNoCollaborativeSignal = errors.New("no articles are saved together with the user's saved articles")
+
+// CollaborativeUseCase はユーザーがメモを書いた記事といいねした記事から、アイテム間の協調フィルタリングを行います
+// 同じユーザーに保存された記事同士の類似度を定期的に計算して保存し、関連記事と推薦に使います
+type CollaborativeUseCase struct {
+	collaborativeRepository repository.ICollaborativeRepository
+	articleRepository       repository.IArticleRepository
+	userRepository          repository.IUserRepository
+	interval                time.Duration
+}
+
+func NewCollaborativeUseCase(collaborativeRepository repository.ICollaborativeRepository, articleRepository repository.IArticleRepository, userRepository repository.IUserRepository) *CollaborativeUseCase {
+	return &CollaborativeUseCase{
+		collaborativeRepository: collaborativeRepository,
+		articleRepository:       articleRepository,
+		userRepository:          userRepository,
+		interval:                durationFromEnv("COLLABORATIVE_INTERVAL", defaultCollaborativeInterval),
+	}
+}
+
+// Start は記事同士の類似度のバックグラウンドでの計算を開始します
+func (u *CollaborativeUseCase) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(u.interval)
+		defer ticker.Stop()
+
+		// 起動直後に一度計算してから、以降は間隔ごとに計算する
+		for {
+			if err := u.Recompute(ctx); err != nil {
+				fmt.Println("🔴 failed to compute article similarities:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Recompute は全てのユーザーの保存した記事から記事同士の類似度を計算し直します
+func (u *CollaborativeUseCase) Recompute(ctx context.Context) error {
+	start := time.Now()
+	saves, err := u.collaborativeRepository.GetUserSaves(ctx)
+	if err != nil {
+		return err
+	}
+
+	similarities := computeItemSimilarities(saves, maxSimilarArticles, time.Now())
+	if err := u.collaborativeRepository.ReplaceSimilarities(ctx, similarities); err != nil {
+		return err
+	}
+
+	fmt.Printf("🟢 computed %d article similarities from %d saves in %s\n", len(similarities), len(saves), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// GetRelatedArticles は記事を保存したユーザーが他に保存した記事を返します
+func (u *CollaborativeUseCase) GetRelatedArticles(ctx context.Context, articleID string, limit int) ([]model.RelatedArticle, error) {
+	if _, err := u.articleRepository.GetArticleByID(ctx, articleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+	if limit > maxRelatedLimit {
+		limit = maxRelatedLimit
+	}
+	return u.collaborativeRepository.GetRelatedArticles(ctx, articleID, limit)
+}
+
+// Recommend はユーザーが保存した記事に似ている候補を、類似度の合計の高い順に limit 件返します
+// ユーザーが既に保存した記事は候補から除き、除いた記事のIDも返します
+func (u *CollaborativeUseCase) Recommend(ctx context.Context, user *model.User, candidates []model.Article, limit int) ([]rankedArticle, map[string]bool, error) {
+	memoIDs, err := u.userRepository.GetMemoArticleIDs(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	saved := make(map[string]bool)
+	var savedIDs []string
+	for _, id := range append(append([]string{}, user.Likes...), memoIDs...) {
+		if !saved[id] {
+			saved[id] = true
+			savedIDs = append(savedIDs, id)
+		}
+	}
+
+	similarities, err := u.collaborativeRepository.GetSimilarities(ctx, savedIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 候補ごとに類似度を合計し、最も寄与した保存済みの記事を理由として残す
+	type score struct {
+		total float64
+		best  float64
+		from  string
+	}
+	scores := make(map[string]*score)
+	for _, similarity := range similarities {
+		if saved[similarity.RelatedArticleID] {
+			continue
+		}
+		s, ok := scores[similarity.RelatedArticleID]
+		if !ok {
+			s = &score{}
+			scores[similarity.RelatedArticleID] = s
+		}
+		s.total += similarity.Score
+		if similarity.Score > s.best {
+			s.best, s.from = similarity.Score, similarity.ArticleID
+		}
+	}
+
+	type scoredArticle struct {
+		article model.Article
+		score   *score
+	}
+	var scored []scoredArticle
+	for _, article := range candidates {
+		if s, ok := scores[article.ID]; ok {
+			scored = append(scored, scoredArticle{article: article, score: s})
+		}
+	}
+	if len(scored) == 0 {
+		return nil, nil, errNoCollaborativeSignal
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score.total > scored[j].score.total
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	ranked := make([]rankedArticle, 0, len(scored))
+	for _, s := range scored {
+		ranked = append(ranked, rankedArticle{Article: s.article, SavedWith: s.score.from})
+	}
+	return ranked, saved, nil
+}
+
+// computeItemSimilarities は記事を保存したユーザーの集合のコサイン類似度
+// (両方を保存したユーザー数 / sqrt(それぞれを保存したユーザー数の積)) を計算し、記事ごとに上位 limit 件を返します
+func computeItemSimilarities(saves []model.UserSave, limit int, now time.Time) []model.ArticleSimilarity {
+	byUser := make(map[string][]string)
+	counts := make(map[string]int)
+	seen := make(map[model.UserSave]bool, len(saves))
+	for _, save := range saves {
+		if seen[save] {
+			continue
+		}
+		seen[save] = true
+		byUser[save.UserID] = append(byUser[save.UserID], save.ArticleID)
+		counts[save.ArticleID]++
+	}
+
+	coCounts := make(map[string]map[string]int)
+	for _, articles := range byUser {
+		for _, a := range articles {
+			for _, b := range articles {
+				if a == b {
+					continue
+				}
+				if coCounts[a] == nil {
+					coCounts[a] = make(map[string]int)
+				}
+				coCounts[a][b]++
+			}
+		}
+	}
+
+	var similarities []model.ArticleSimilarity
+	for a, related := range coCounts {
+		row := make([]model.ArticleSimilarity, 0, len(related))
+		for b, co := range related {
+			row = append(row, model.ArticleSimilarity{
+				ArticleID:        a,
+				RelatedArticleID: b,
+				Score:            float64(co) / math.Sqrt(float64(counts[a]*counts[b])),
+				CoCount:          co,
+				UpdatedAt:        now,
+			})
+		}
+		sort.Slice(row, func(i, j int) bool {
+			if row[i].Score != row[j].Score {
+				return row[i].Score > row[j].Score
+			}
+			if row[i].CoCount != row[j].CoCount {
+				return row[i].CoCount > row[j].CoCount
+			}
+			return row[i].RelatedArticleID < row[j].RelatedArticleID
+		})
+		if len(row) > limit {
+			row = row[:limit]
+		}
+		similarities = append(similarities, row...)
+	}
+	return similarities
+}
@@ -0,0 +1,391 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// extractedContent は記事ページから抽出した本文です
+type extractedContent struct {
+	title string
+	text  string
+	html  string
+}
+
+var (
+	// positiveCandidatePattern は本文らしい class / id です
+	positiveCandidatePattern = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|text|blog|story`)
+	// negativeCandidatePattern は本文ではない可能性が高い class / id です
+	negativeCandidatePattern = regexp.MustCompile(`(?i)comment|com-|contact|foot|footer|footnote|masthead|media|meta|nav|menu|promo|related|scroll|share|shoutbox|sidebar|sponsor|shopping|tags|tool|widget|ad-|ads|banner|breadcrumb|pager|popup`)
+	whitespacePattern        = regexp.MustCompile(`\s+`)
+)
+
+// removedElements は本文の判定前に取り除く要素です
+var removedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Form:     true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+	atom.Header:   true,
+	atom.Svg:      true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+}
+
+// allowedElements はサニタイズ後のHTMLに残す要素と、その要素で許可する属性です
+var allowedElements = map[atom.Atom][]string{
+	atom.P:          nil,
+	atom.Br:         nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Ul:         nil,
+	atom.Ol:         nil,
+	atom.Li:         nil,
+	atom.Pre:        nil,
+	atom.Code:       nil,
+	atom.Blockquote: nil,
+	atom.Em:         nil,
+	atom.Strong:     nil,
+	atom.B:          nil,
+	atom.I:          nil,
+	atom.Table:      nil,
+	atom.Thead:      nil,
+	atom.Tbody:      nil,
+	atom.Tr:         nil,
+	atom.Th:         nil,
+	atom.Td:         nil,
+	atom.Figure:     nil,
+	atom.Figcaption: nil,
+	atom.A:          {"href"},
+	atom.Img:        {"src", "alt"},
+}
+
+// blockElements はプレーンテキストに変換する際に改行を入れる要素です
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Pre: true, atom.Blockquote: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Tr: true, atom.Table: true, atom.Section: true, atom.Article: true, atom.Figure: true,
+}
+
+// extractContent は Readability と同様の手法で、HTMLから本文と思われる部分を抽出します
+// 段落ごとにテキスト量と句読点の数でスコアを付けて親要素に加算し、最もスコアの高い要素を本文とします
+func extractContent(r io.Reader, pageURL string) (*extractedContent, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	base, _ := url.Parse(pageURL)
+	title := findTitle(doc)
+	removeUnlikelyNodes(doc)
+
+	scores := make(map[*html.Node]float64)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Td) {
+			scoreParagraph(n, scores)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var best *html.Node
+	bestScore := math.Inf(-1)
+	for node, score := range scores {
+		// リンクばかりの要素 (目次やリンク集) は本文とみなさない
+		score *= 1 - linkDensity(node)
+		if score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	if best == nil {
+		best = findBody(doc)
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no readable content found")
+	}
+
+	var htmlBuilder strings.Builder
+	renderSanitized(&htmlBuilder, best, base)
+
+	text := strings.TrimSpace(nodeText(best))
+	if text == "" {
+		return nil, fmt.Errorf("no readable content found")
+	}
+
+	return &extractedContent{
+		title: title,
+		text:  text,
+		html:  strings.TrimSpace(htmlBuilder.String()),
+	}, nil
+}
+
+func scoreParagraph(n *html.Node, scores map[*html.Node]float64) {
+	text := collapseWhitespace(innerText(n))
+	length := len([]rune(text))
+	if length < 25 {
+		return
+	}
+
+	// 文字数と読点・カンマの数が多いほど本文らしい
+	score := 1.0
+	score += float64(strings.Count(text, ",") + strings.Count(text, "、") + strings.Count(text, "。"))
+	score += math.Min(float64(length)/100, 3)
+
+	parent := n.Parent
+	if parent == nil {
+		return
+	}
+	if _, ok := scores[parent]; !ok {
+		scores[parent] = initialNodeScore(parent)
+	}
+	scores[parent] += score
+
+	if grandParent := parent.Parent; grandParent != nil && grandParent.Type == html.ElementNode {
+		if _, ok := scores[grandParent]; !ok {
+			scores[grandParent] = initialNodeScore(grandParent)
+		}
+		scores[grandParent] += score / 2
+	}
+}
+
+func initialNodeScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Article:
+		score += 10
+	case atom.Div, atom.Main, atom.Section:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+
+	hint := attr(n, "class") + " " + attr(n, "id")
+	if negativeCandidatePattern.MatchString(hint) {
+		score -= 25
+	}
+	if positiveCandidatePattern.MatchString(hint) {
+		score += 25
+	}
+	return score
+}
+
+// removeUnlikelyNodes はスクリプトやナビゲーションなど本文ではない要素を取り除きます
+func removeUnlikelyNodes(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || (c.Type == html.ElementNode && removedElements[c.DataAtom]) {
+			n.RemoveChild(c)
+		} else {
+			removeUnlikelyNodes(c)
+		}
+		c = next
+	}
+}
+
+func linkDensity(n *html.Node) float64 {
+	textLength := len([]rune(collapseWhitespace(innerText(n))))
+	if textLength == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			linkLength += len([]rune(collapseWhitespace(innerText(n))))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return float64(linkLength) / float64(textLength)
+}
+
+func findTitle(doc *html.Node) string {
+	var title string
+	var walk func(n *html.Node) bool
+	walk = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Title {
+			title = collapseWhitespace(innerText(n))
+			return true
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if walk(c) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(doc)
+	return strings.TrimSpace(title)
+}
+
+func findBody(doc *html.Node) *html.Node {
+	var body *html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if body != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+			body = n
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return body
+}
+
+// renderSanitized は許可された要素と属性のみを残してHTMLを書き出します
+// 許可されていない要素はタグを取り除き、中身のみを残します
+func renderSanitized(sb *strings.Builder, n *html.Node, base *url.URL) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			sb.WriteString(html.EscapeString(c.Data))
+		case html.ElementNode:
+			allowedAttrs, allowed := allowedElements[c.DataAtom]
+			if !allowed {
+				renderSanitized(sb, c, base)
+				continue
+			}
+
+			sb.WriteString("<" + c.Data)
+			for _, name := range allowedAttrs {
+				value := attr(c, name)
+				if value == "" {
+					continue
+				}
+				if name == "href" || name == "src" {
+					value = resolveSafeURL(base, value)
+					if value == "" {
+						continue
+					}
+				}
+				sb.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+			}
+			if c.DataAtom == atom.A {
+				sb.WriteString(` rel="noopener noreferrer nofollow"`)
+			}
+			sb.WriteString(">")
+
+			if c.DataAtom == atom.Br || c.DataAtom == atom.Img {
+				continue
+			}
+			renderSanitized(sb, c, base)
+			sb.WriteString("</" + c.Data + ">")
+		}
+	}
+}
+
+// resolveSafeURL は相対URLを絶対URLに変換し、http(s) 以外のURL (javascript: など) を除外します
+func resolveSafeURL(base *url.URL, value string) string {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// nodeText は段落ごとに改行を入れたプレーンテキストを返します
+func nodeText(n *html.Node) string {
+	var lines []string
+	var current strings.Builder
+	flush := func() {
+		line := strings.TrimSpace(collapseWhitespace(current.String()))
+		if line != "" {
+			lines = append(lines, line)
+		}
+		current.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			current.WriteString(n.Data)
+			return
+		}
+		isBlock := n.Type == html.ElementNode && blockElements[n.DataAtom]
+		if isBlock {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if isBlock {
+			flush()
+		}
+	}
+	walk(n)
+	flush()
+
+	return strings.Join(lines, "\n\n")
+}
+
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteString(" ")
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+func collapseWhitespace(s string) string {
+	return whitespacePattern.ReplaceAllString(s, " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
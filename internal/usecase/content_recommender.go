@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"SmartBook/internal/search"
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// 記事の文書ベクトルを作る時のフィールドごとの重みです
+	tfidfTitleWeight   = 3.0
+	tfidfTagWeight     = 2.0
+	tfidfContentWeight = 1.0
+	// tfidfMaxContentRunes は文書ベクトルに使う本文の最大文字数です
+	tfidfMaxContentRunes = 5000
+	// ユーザーのプロフィールベクトルを作る時の行動ごとの重みです
+	profileLikeWeight     = 3.0
+	profileMemoWeight     = 2.0
+	profileViewWeight     = 1.0
+	profileInterestWeight = 2.0
+	// defaultRecommendationHalfLife は記事の新しさによる減衰の半減期の既定値です
+	defaultRecommendationHalfLife = 72 * time.Hour
+	// termVectorCacheTTL は記事ごとの語の出現回数をキャッシュする時間です
+	termVectorCacheTTL = time.Hour
+	// maxReasonTerms は推薦の理由に含める共通の語の数です
+	maxReasonTerms = 3
+)
+
+// errEmptyProfile はいいね・閲覧・メモ・興味のいずれもなく、プロフィールベクトルを作れない場合のエラーです
+var errEmptyProfile = errors.New("user has no likes, views, memos or interests to build a profile from")
+
+// termVector は語ごとの重みです
+type termVector map[string]float64
+
+// idfTable は語ごとの IDF と、その最大値です
+type idfTable struct {
+	values map[string]float64
+	max    float64
+}
+
+// ContentRecommender はタイトル・タグ・抽出した本文の TF-IDF ベクトルで記事を推薦します
+// いいね・閲覧・メモした記事と興味からユーザーのプロフィールベクトルを作り、コサイン類似度に記事の新しさによる減衰を掛けて並べます
+type ContentRecommender struct {
+	articleRepository repository.IArticleRepository
+	contentRepository repository.IContentRepository
+	userRepository    repository.IUserRepository
+	cache             Cache
+	halfLife          time.Duration
+}
+
+func NewContentRecommender(articleRepository repository.IArticleRepository, contentRepository repository.IContentRepository, userRepository repository.IUserRepository, cache Cache) *ContentRecommender {
+	return &ContentRecommender{
+		articleRepository: articleRepository,
+		contentRepository: contentRepository,
+		userRepository:    userRepository,
+		cache:             cache,
+		halfLife:          durationFromEnv("RECOMMENDER_HALF_LIFE", defaultRecommendationHalfLife),
+	}
+}
+
+// Recommend は候補のうちユーザーのプロフィールに近い記事を limit 件返します
+// いいね・閲覧・メモした記事は候補から除き、除いた記事のIDも返します
+func (r *ContentRecommender) Recommend(ctx context.Context, user *model.User, candidates []model.Article, limit int) ([]rankedArticle, map[string]bool, error) {
+	memoIDs, err := r.userRepository.GetMemoArticleIDs(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 行動ごとの重み。同じ記事に複数の行動がある場合は重みを足す
+	weights := make(map[string]float64)
+	for _, id := range user.Likes {
+		weights[id] += profileLikeWeight
+	}
+	for _, id := range memoIDs {
+		weights[id] += profileMemoWeight
+	}
+	for _, id := range user.RecentViews {
+		weights[id] += profileViewWeight
+	}
+	if len(weights) == 0 && len(user.Interests) == 0 {
+		return nil, nil, errEmptyProfile
+	}
+
+	profileIDs := make([]string, 0, len(weights))
+	for id := range weights {
+		profileIDs = append(profileIDs, id)
+	}
+	profileArticles, err := r.articleRepository.GetArticlesByIDs(ctx, profileIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excluded := make(map[string]bool, len(weights))
+	for id := range weights {
+		excluded[id] = true
+	}
+	remaining := make([]model.Article, 0, len(candidates))
+	for _, article := range candidates {
+		if weights[article.ID] == 0 {
+			remaining = append(remaining, article)
+		}
+	}
+
+	termCounts, err := r.termCounts(ctx, append(append([]model.Article{}, remaining...), profileArticles...))
+	if err != nil {
+		return nil, nil, err
+	}
+	idf := inverseDocumentFrequency(termCounts)
+
+	// プロフィールベクトルは行動の重みを付けた記事ベクトルと興味の語の和
+	profile := make(termVector)
+	for _, article := range profileArticles {
+		addScaled(profile, tfidfVector(termCounts[article.ID], idf), weights[article.ID])
+	}
+	interests := make(termVector)
+	for _, interest := range user.Interests {
+		for _, token := range search.Tokenize(interest) {
+			interests[token]++
+		}
+	}
+	addScaled(profile, tfidfVector(interests, idf), profileInterestWeight)
+	normalize(profile)
+	if len(profile) == 0 {
+		return nil, nil, errEmptyProfile
+	}
+
+	now := time.Now()
+	type scoredArticle struct {
+		rankedArticle
+		score float64
+	}
+	scored := make([]scoredArticle, 0, len(remaining))
+	for _, article := range remaining {
+		vector := tfidfVector(termCounts[article.ID], idf)
+		similarity := cosine(profile, vector)
+		if similarity <= 0 {
+			continue
+		}
+		scored = append(scored, scoredArticle{
+			rankedArticle: rankedArticle{Article: article, Terms: topSharedTerms(profile, vector, maxReasonTerms)},
+			score:         similarity * r.decay(now, article.CreatedAt),
+		})
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	ranked := make([]rankedArticle, 0, min(limit, len(scored)))
+	for i := 0; i < limit && i < len(scored); i++ {
+		ranked = append(ranked, scored[i].rankedArticle)
+	}
+	return ranked, excluded, nil
+}
+
+// decay は記事の新しさによる減衰で、半減期ごとに半分になります
+func (r *ContentRecommender) decay(now time.Time, createdAt time.Time) float64 {
+	age := now.Sub(createdAt)
+	if age <= 0 || r.halfLife <= 0 {
+		return 1
+	}
+	return math.Exp2(-age.Hours() / r.halfLife.Hours())
+}
+
+// termCounts は記事ごとの語の出現回数 (フィールドの重み付き) を返します
+// 本文の分割は重いため、記事ごとの結果をキャッシュします
+func (r *ContentRecommender) termCounts(ctx context.Context, articles []model.Article) (map[string]termVector, error) {
+	counts := make(map[string]termVector, len(articles))
+	var missing []model.Article
+	for _, article := range articles {
+		if _, ok := counts[article.ID]; ok {
+			continue
+		}
+		if cached, found := r.cache.Get(termVectorCacheKey(article.ID)); found {
+			counts[article.ID] = cached.(termVector)
+			continue
+		}
+		counts[article.ID] = nil
+		missing = append(missing, article)
+	}
+	if len(missing) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]string, 0, len(missing))
+	for _, article := range missing {
+		ids = append(ids, article.ID)
+	}
+	texts, err := r.contentRepository.GetContentTexts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, article := range missing {
+		vector := make(termVector)
+		for _, token := range search.Tokenize(article.Title) {
+			vector[token] += tfidfTitleWeight
+		}
+		for _, token := range search.Tokenize(strings.Join(article.Tags, " ")) {
+			vector[token] += tfidfTagWeight
+		}
+		text := texts[article.ID]
+		if runes := []rune(text); len(runes) > tfidfMaxContentRunes {
+			text = string(runes[:tfidfMaxContentRunes])
+		}
+		for _, token := range search.Tokenize(text) {
+			vector[token] += tfidfContentWeight
+		}
+		counts[article.ID] = vector
+		r.cache.Set(termVectorCacheKey(article.ID), vector, termVectorCacheTTL)
+	}
+	return counts, nil
+}
+
+func termVectorCacheKey(articleID string) string {
+	return "tfidf_terms:" + articleID
+}
+
+// inverseDocumentFrequency は記事の集合から語ごとの IDF (smooth idf) を計算します
+func inverseDocumentFrequency(termCounts map[string]termVector) idfTable {
+	df := make(map[string]int)
+	for _, counts := range termCounts {
+		for term := range counts {
+			df[term]++
+		}
+	}
+
+	n := float64(len(termCounts))
+	idf := idfTable{values: make(map[string]float64, len(df)), max: 1}
+	for term, count := range df {
+		value := math.Log((1+n)/(1+float64(count))) + 1
+		idf.values[term] = value
+		idf.max = math.Max(idf.max, value)
+	}
+	return idf
+}
+
+// tfidfVector は語の出現回数から L2 正規化した TF-IDF ベクトルを作ります。TF は 1 + log(回数) です
+// 記事の集合に現れない語 (興味の語など) は IDF を最大として扱います
+func tfidfVector(counts termVector, idf idfTable) termVector {
+	vector := make(termVector, len(counts))
+	for term, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		weight, ok := idf.values[term]
+		if !ok {
+			weight = idf.max
+		}
+		vector[term] = (1 + math.Log(count)) * weight
+	}
+	normalize(vector)
+	return vector
+}
+
+func addScaled(dst termVector, src termVector, scale float64) {
+	for term, value := range src {
+		dst[term] += value * scale
+	}
+}
+
+func normalize(vector termVector) {
+	var sum float64
+	for _, value := range vector {
+		sum += value * value
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for term := range vector {
+		vector[term] /= norm
+	}
+}
+
+// cosine は正規化したベクトル同士のコサイン類似度です
+func cosine(a termVector, b termVector) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, value := range a {
+		dot += value * b[term]
+	}
+	return dot
+}
+
+// topSharedTerms は類似度への寄与が大きい共通の語を n 個まで返します
+// 日本語は正規化した bigram (ひらがなに変換済み) で表示に向かないため、英数字の語のみを対象にします
+func topSharedTerms(profile termVector, vector termVector, n int) []string {
+	type contribution struct {
+		term  string
+		value float64
+	}
+	var shared []contribution
+	for term, value := range vector {
+		if !isDisplayTerm(term) {
+			continue
+		}
+		if p, ok := profile[term]; ok {
+			shared = append(shared, contribution{term: term, value: p * value})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].value != shared[j].value {
+			return shared[i].value > shared[j].value
+		}
+		return shared[i].term < shared[j].term
+	})
+
+	terms := make([]string, 0, n)
+	for i := 0; i < n && i < len(shared); i++ {
+		terms = append(terms, shared[i].term)
+	}
+	return terms
+}
+
+func isDisplayTerm(term string) bool {
+	if len([]rune(term)) < 2 {
+		return false
+	}
+	for _, r := range term {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+	"gorm.io/gorm"
+)
+
+// maxContentBytes は本文抽出のためにダウンロードするHTMLの最大サイズです
+const maxContentBytes = 5 << 20
+
+var (
+	ErrArticleNotFound = errors.New("article not found")
+	// ErrNoArticleURL は Ask HN など、リンク先を持たない記事で返されます
+	ErrNoArticleURL = errors.New("article has no url")
+	// ErrUnknownSource はメモの作成で登録された記事など、取り込んだソースの記事でない場合に返されます
+	ErrUnknownSource = errors.New("article is not from an ingested source")
+)
+
+// contentFetchTimeout は本文抽出で記事のページを取得する時間の上限です
+const contentFetchTimeout = 10 * time.Second
+
+type ContentUseCase struct {
+	client            *http.Client
+	sourceRegistry    *SourceRegistry
+	articleRepository repository.IArticleRepository
+	contentRepository repository.IContentRepository
+}
+
+// NewContentUseCase は ContentUseCase を作成します
+// 記事のページは公開されたアドレスにのみ接続するクライアントで取得します
+func NewContentUseCase(sourceRegistry *SourceRegistry, articleRepository repository.IArticleRepository, contentRepository repository.IContentRepository) *ContentUseCase {
+	return &ContentUseCase{
+		client:            newPublicHTTPClient(contentFetchTimeout),
+		sourceRegistry:    sourceRegistry,
+		articleRepository: articleRepository,
+		contentRepository: contentRepository,
+	}
+}
+
+// GetArticleContent は記事の本文を返します
+// 初回は記事のページをダウンロードして本文を抽出し、以降は保存した本文を返します
+func (u *ContentUseCase) GetArticleContent(ctx context.Context, articleID string) (*model.ArticleContent, error) {
+	content, err := u.contentRepository.GetContent(ctx, articleID)
+	if err == nil {
+		return &content, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load content: %w", err)
+	}
+
+	article, err := u.articleRepository.GetArticleByID(ctx, articleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("failed to load article: %w", err)
+	}
+	if article.URL == "" {
+		return nil, ErrNoArticleURL
+	}
+	// URL をユーザーが指定できる記事 (メモの作成で登録された記事など) はサーバーから取得しない
+	if !u.sourceRegistry.Has(article.Source) {
+		return nil, ErrUnknownSource
+	}
+
+	extracted, err := u.fetchAndExtract(ctx, article.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	title := extracted.title
+	if title == "" {
+		title = article.Title
+	}
+	content = model.ArticleContent{
+		ArticleID:   article.ID,
+		URL:         article.URL,
+		Title:       title,
+		Text:        extracted.text,
+		HTML:        extracted.html,
+		ExtractedAt: time.Now(),
+	}
+	if err := u.contentRepository.SaveContent(ctx, &content); err != nil {
+		// 保存に失敗しても抽出した本文は返す
+		fmt.Println("🔴 failed to save article content:", err)
+	}
+
+	return &content, nil
+}
+
+func (u *ContentUseCase) fetchAndExtract(ctx context.Context, pageURL string) (*extractedContent, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := checkPublicURL(req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "SmartBook/1.0 (+content extraction)")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch page: unexpected status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	// Shift_JIS などのページも UTF-8 に変換して解析する
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxContentBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect charset: %w", err)
+	}
+
+	return extractContent(body, resp.Request.URL.String())
+}
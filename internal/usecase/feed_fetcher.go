@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// FeedFetcher は RSS 2.0 / RSS 1.0 / Atom フィードから記事を取得します
+type FeedFetcher struct {
+	client *http.Client
+	name   string
+	url    string
+	policy retryPolicy
+}
+
+func NewFeedFetcher(client *http.Client, feed model.FeedSource) *FeedFetcher {
+	return &FeedFetcher{
+		client: client,
+		name:   feed.Name,
+		url:    feed.URL,
+		policy: defaultRetryPolicy,
+	}
+}
+
+// feedItem は RSS と Atom のエントリを共通化したものです
+type feedItem struct {
+	guid       string
+	title      string
+	link       string
+	author     string
+	published  string
+	categories []string
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 (RDF) では item が channel の外に置かれる
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title      string   `xml:"title"`
+	Link       string   `xml:"link"`
+	GUID       string   `xml:"guid"`
+	About      string   `xml:"about,attr"`
+	PubDate    string   `xml:"pubDate"`
+	Date       string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author     string   `xml:"author"`
+	Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories []string `xml:"category"`
+	Subjects   []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+type atomDocument struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Links     []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Author struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// feedTimeLayouts はフィードで使われる日時の書式です
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02",
+}
+
+func (f *FeedFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Article, error) {
+	body, err := getWithRetry(ctx, f.client, f.url, nil, f.policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	items, feedTitle, err := parseFeed(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	articles := make([]model.Article, 0, len(items))
+	for _, item := range items {
+		if len(articles) >= limit {
+			break
+		}
+
+		guid := item.guid
+		if guid == "" {
+			guid = item.link
+		}
+		if guid == "" {
+			continue
+		}
+
+		author := item.author
+		if author == "" {
+			author = feedTitle
+		}
+		if author == "" {
+			author = f.name
+		}
+
+		articles = append(articles, model.Article{
+			ID:        feedArticleID(f.url, guid),
+			Title:     item.title,
+			URL:       item.link,
+			Author:    author,
+			CreatedAt: parseFeedTime(item.published),
+			Source:    f.name,
+			Tags:      item.categories,
+		})
+	}
+
+	return articles, nil
+}
+
+// parseFeed はルート要素から RSS か Atom かを判定してエントリを取り出します
+func parseFeed(body []byte) ([]feedItem, string, error) {
+	root, err := feedRootElement(body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch root.Local {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := decodeFeed(body, &doc); err != nil {
+			return nil, "", err
+		}
+		items := append(doc.Channel.Items, doc.Items...)
+		result := make([]feedItem, 0, len(items))
+		for _, item := range items {
+			guid := item.GUID
+			if guid == "" {
+				guid = item.About
+			}
+			published := item.PubDate
+			if published == "" {
+				published = item.Date
+			}
+			author := item.Author
+			if author == "" {
+				author = item.Creator
+			}
+			result = append(result, feedItem{
+				guid:       strings.TrimSpace(guid),
+				title:      strings.TrimSpace(item.Title),
+				link:       strings.TrimSpace(item.Link),
+				author:     strings.TrimSpace(author),
+				published:  strings.TrimSpace(published),
+				categories: compactStrings(append(item.Categories, item.Subjects...)),
+			})
+		}
+		return result, strings.TrimSpace(doc.Channel.Title), nil
+
+	case "feed":
+		var doc atomDocument
+		if err := decodeFeed(body, &doc); err != nil {
+			return nil, "", err
+		}
+		result := make([]feedItem, 0, len(doc.Entries))
+		for _, entry := range doc.Entries {
+			link := ""
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+			categories := make([]string, 0, len(entry.Categories))
+			for _, c := range entry.Categories {
+				categories = append(categories, c.Term)
+			}
+			result = append(result, feedItem{
+				guid:       strings.TrimSpace(entry.ID),
+				title:      strings.TrimSpace(entry.Title),
+				link:       strings.TrimSpace(link),
+				author:     strings.TrimSpace(entry.Author.Name),
+				published:  strings.TrimSpace(published),
+				categories: compactStrings(categories),
+			})
+		}
+		return result, strings.TrimSpace(doc.Title), nil
+
+	default:
+		return nil, "", fmt.Errorf("unsupported feed format: <%s>", root.Local)
+	}
+}
+
+func feedRootElement(body []byte) (xml.Name, error) {
+	decoder := newFeedDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func decodeFeed(body []byte, dest interface{}) error {
+	return newFeedDecoder(body).Decode(dest)
+}
+
+// newFeedDecoder は UTF-8 以外 (Shift_JIS, EUC-JP など) のフィードも読めるデコーダを作成します
+func newFeedDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	return decoder
+}
+
+// feedArticleID はフィードURLとGUIDから安定した記事IDを作成します
+func feedArticleID(feedURL, guid string) string {
+	sum := sha1.Sum([]byte(feedURL + "\n" + guid))
+	return "feed_" + hex.EncodeToString(sum[:])[:16]
+}
+
+func parseFeedTime(value string) time.Time {
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	// 日時が読めない場合は取り込んだ時刻を作成日時とする
+	// 既に保存されている記事の作成日時は UpsertArticles で更新しないため、最初に取り込んだ時刻のままになる
+	return time.Now()
+}
+
+func compactStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryPolicy は外部APIへのリクエストのタイムアウトと再試行の設定です
+type retryPolicy struct {
+	// attemptTimeout は1回のリクエストのタイムアウトです
+	attemptTimeout time.Duration
+	// maxRetries は最初のリクエストを除いた再試行回数です
+	maxRetries int
+	// baseBackoff は再試行までの待ち時間の基準値で、試行ごとに倍になります
+	baseBackoff time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	attemptTimeout: 5 * time.Second,
+	maxRetries:     3,
+	baseBackoff:    200 * time.Millisecond,
+}
+
+// httpStatusError は 2xx 以外のレスポンスを表します
+type httpStatusError struct {
+	statusCode int
+	retryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.statusCode)
+}
+
+// retryable は 5xx と 429 のみ再試行の対象とします
+func (e *httpStatusError) retryable() bool {
+	return e.statusCode == http.StatusTooManyRequests || e.statusCode >= 500
+}
+
+// getJSONWithRetry は url に GET リクエストを送り、レスポンスを dest にデコードします
+func getJSONWithRetry(ctx context.Context, client *http.Client, url string, header http.Header, policy retryPolicy, dest interface{}) error {
+	body, err := getWithRetry(ctx, client, url, header, policy)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, dest); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// getWithRetry は url に GET リクエストを送り、レスポンスボディを返します
+// ネットワークエラーと 5xx / 429 の場合は指数バックオフで再試行します
+func getWithRetry(ctx context.Context, client *http.Client, url string, header http.Header, policy retryPolicy) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= policy.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := policy.backoff(attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			case <-time.After(wait):
+			}
+		}
+
+		body, err := getOnce(ctx, client, url, header, policy.attemptTimeout)
+		if err == nil {
+			return body, nil
+		}
+
+		lastErr = err
+		if statusErr, ok := err.(*httpStatusError); ok && !statusErr.retryable() {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+		}
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", policy.maxRetries+1, lastErr)
+}
+
+func getOnce(ctx context.Context, client *http.Client, url string, header http.Header, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// 接続を再利用できるようにボディを読み捨てる
+		io.Copy(io.Discard, resp.Body)
+		return nil, &httpStatusError{
+			statusCode: resp.StatusCode,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// backoff は attempt 回目の再試行までの待ち時間を返します
+func (p retryPolicy) backoff(attempt int, lastErr error) time.Duration {
+	wait := p.baseBackoff << (attempt - 1)
+	// 同時に失敗したリクエストが一斉に再試行しないよう揺らぎを加える
+	wait += time.Duration(rand.Int63n(int64(p.baseBackoff)))
+
+	if statusErr, ok := lastErr.(*httpStatusError); ok && statusErr.retryAfter > wait {
+		wait = statusErr.retryAfter
+	}
+	return wait
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
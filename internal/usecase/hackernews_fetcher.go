@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	hackerNewsBaseURL = "https://hacker-news.firebaseio.com/v0"
+	// hackerNewsWorkers は記事を同時に取得するワーカー数です
+	hackerNewsWorkers = 16
+)
+
+type HackerNewsFetcher struct {
+	client  *http.Client
+	baseURL string
+	workers int
+	policy  retryPolicy
+
+	mu        sync.Mutex
+	lastStats model.FetchStats
+	// totalDropped は起動してから取りこぼした記事の累計です
+	totalDropped atomic.Int64
+}
+
+func NewHackerNewsFetcher(client *http.Client) *HackerNewsFetcher {
+	return &HackerNewsFetcher{
+		client:  client,
+		baseURL: hackerNewsBaseURL,
+		workers: hackerNewsWorkers,
+		policy:  defaultRetryPolicy,
+	}
+}
+
+// FetchArticles はトップストーリーを最大 limit 件取得します
+// 各記事はワーカープールで並行して取得し、取得できなかった記事は件数を記録して除外します
+func (f *HackerNewsFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Article, error) {
+	var ids []int
+	if err := getJSONWithRetry(ctx, f.client, f.baseURL+"/topstories.json", nil, f.policy, &ids); err != nil {
+		return nil, fmt.Errorf("failed to fetch top stories: %w", err)
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	// ランキング順を保つため、結果は元のインデックスに格納する
+	results := make([]*model.Article, len(ids))
+	jobs := make(chan int)
+
+	var dropped atomic.Int64
+	var firstErr atomic.Value
+	var wg sync.WaitGroup
+	for w := 0; w < f.workers && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				article, err := f.getHackerNewsArticleByID(ctx, ids[i])
+				if err != nil {
+					dropped.Add(1)
+					firstErr.CompareAndSwap(nil, err.Error())
+					continue
+				}
+				results[i] = &article
+			}
+		}()
+	}
+
+sendLoop:
+	for i := range ids {
+		select {
+		case <-ctx.Done():
+			// 送信できなかった残りの記事も取りこぼしとして数える
+			dropped.Add(int64(len(ids) - i))
+			break sendLoop
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	articles := make([]model.Article, 0, len(ids))
+	for _, article := range results {
+		if article != nil {
+			articles = append(articles, *article)
+		}
+	}
+
+	stats := model.FetchStats{
+		Requested: len(ids),
+		Fetched:   len(articles),
+		Dropped:   int(dropped.Load()),
+	}
+	if sample, ok := firstErr.Load().(string); ok {
+		stats.SampleError = sample
+	}
+	f.totalDropped.Add(int64(stats.Dropped))
+	stats.TotalDropped = f.totalDropped.Load()
+
+	f.mu.Lock()
+	f.lastStats = stats
+	f.mu.Unlock()
+
+	if stats.Dropped > 0 {
+		fmt.Printf("🟡 Hacker News: dropped %d/%d items (e.g. %s)\n", stats.Dropped, stats.Requested, stats.SampleError)
+	}
+
+	if len(articles) == 0 && len(ids) > 0 {
+		return nil, fmt.Errorf("failed to fetch any of %d items: %s", len(ids), stats.SampleError)
+	}
+
+	return articles, nil
+}
+
+// LastFetchStats は直前の FetchArticles の取得結果の件数を返します
+func (f *HackerNewsFetcher) LastFetchStats() model.FetchStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastStats
+}
+
+func (f *HackerNewsFetcher) getHackerNewsArticleByID(ctx context.Context, id int) (model.Article, error) {
+	url := fmt.Sprintf("%s/item/%d.json", f.baseURL, id)
+
+	var articleData struct {
+		ID      int    `json:"id"`
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Score   int    `json:"score"`
+		By      string `json:"by"`
+		Time    int64  `json:"time"`
+		Deleted bool   `json:"deleted"`
+		Dead    bool   `json:"dead"`
+	}
+	if err := getJSONWithRetry(ctx, f.client, url, nil, f.policy, &articleData); err != nil {
+		return model.Article{}, fmt.Errorf("failed to fetch item %d: %w", id, err)
+	}
+
+	// 削除済みの記事は null や deleted として返ってくる
+	if articleData.ID == 0 || articleData.Deleted || articleData.Dead {
+		return model.Article{}, fmt.Errorf("item %d is deleted", id)
+	}
+
+	return model.Article{
+		ID:        fmt.Sprintf("hn_%d", articleData.ID),
+		Title:     articleData.Title,
+		URL:       articleData.URL,
+		Score:     articleData.Score,
+		Author:    articleData.By,
+		CreatedAt: time.Unix(articleData.Time, 0),
+		Source:    "Hacker News",
+	}, nil
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fetchStatsReporter は直前の取得で取りこぼした件数などを報告できる ArticleFetcher です
+type fetchStatsReporter interface {
+	LastFetchStats() model.FetchStats
+}
+
+// IngestionUseCase は記事ソースごとに定期的に記事を取得し、DBへ保存します
+type IngestionUseCase struct {
+	sources           []SourceConfig
+	articleRepository repository.IArticleRepository
+	cache             Cache
+	mu                sync.RWMutex
+	statuses          map[string]model.SourceStatus
+	// newArticleHandlers は新しい記事が保存されるたびに呼び出されます
+	newArticleHandlers []func(ctx context.Context, articleIDs []string)
+}
+
+func NewIngestionUseCase(sources []SourceConfig, articleRepository repository.IArticleRepository, cache Cache) *IngestionUseCase {
+	statuses := make(map[string]model.SourceStatus, len(sources))
+	for _, source := range sources {
+		statuses[source.Name] = model.SourceStatus{Name: source.Name}
+	}
+
+	return &IngestionUseCase{
+		sources:           sources,
+		articleRepository: articleRepository,
+		cache:             cache,
+		statuses:          statuses,
+	}
+}
+
+// OnNewArticles は新しい記事が保存された時に呼び出す関数を登録します。Start の前に呼び出してください
+func (u *IngestionUseCase) OnNewArticles(handler func(ctx context.Context, articleIDs []string)) {
+	u.newArticleHandlers = append(u.newArticleHandlers, handler)
+}
+
+// Start はソースごとに取り込みを開始します。ctx がキャンセルされるまで動き続けます
+func (u *IngestionUseCase) Start(ctx context.Context) {
+	for _, source := range u.sources {
+		go u.run(ctx, source)
+	}
+}
+
+func (u *IngestionUseCase) run(ctx context.Context, source SourceConfig) {
+	ticker := time.NewTicker(source.Interval)
+	defer ticker.Stop()
+
+	// 起動直後に一度取り込んでから、以降は間隔ごとに取り込む
+	for {
+		u.ingest(ctx, source)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *IngestionUseCase) ingest(ctx context.Context, source SourceConfig) {
+	ctx, cancel := context.WithTimeout(ctx, source.Interval)
+	defer cancel()
+
+	startedAt := time.Now()
+	articles, err := source.Fetcher.FetchArticles(ctx, source.DefaultLimit)
+	var insertedIDs []string
+	if err == nil {
+		insertedIDs, err = u.articleRepository.UpsertArticles(ctx, articles)
+	}
+
+	u.mu.Lock()
+	status := u.statuses[source.Name]
+	status.LastRunAt = startedAt
+	status.LastDuration = time.Since(startedAt).String()
+	if reporter, ok := source.Fetcher.(fetchStatsReporter); ok {
+		stats := reporter.LastFetchStats()
+		status.LastFetchStats = &stats
+	}
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+	} else {
+		status.LastSuccessAt = startedAt
+		status.LastError = ""
+		status.LastCount = len(articles)
+		status.ConsecutiveFailures = 0
+	}
+	u.statuses[source.Name] = status
+	u.mu.Unlock()
+
+	if err != nil {
+		fmt.Printf("🔴 Ingestion failed: %s: %s\n", source.Name, err)
+		return
+	}
+
+	// 新しい記事が読まれるようにキャッシュを破棄する
+	u.cache.Delete("all_articles")
+	fmt.Printf("🟢 Ingested %d articles (%d new) from %s\n", len(articles), len(insertedIDs), source.Name)
+
+	if len(insertedIDs) > 0 {
+		for _, handler := range u.newArticleHandlers {
+			handler(ctx, insertedIDs)
+		}
+	}
+}
+
+// Status は指定したソースの最終実行状況を返します
+func (u *IngestionUseCase) Status(name string) model.SourceStatus {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	if status, ok := u.statuses[name]; ok {
+		return status
+	}
+	return model.SourceStatus{Name: name}
+}
+
+// Statuses はソースごとの最終実行状況を名前順で返します
+func (u *IngestionUseCase) Statuses() []model.SourceStatus {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	statuses := make([]model.SourceStatus, 0, len(u.statuses))
+	for _, status := range u.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// durationFromEnv は環境変数から取り込み間隔を読み込みます。未設定や不正な値の場合は def を返します
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		fmt.Printf("🟡 invalid duration %s=%q, using %s\n", key, value, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxDwellSeconds は記録する閲覧時間の上限です (1日)
+const maxDwellSeconds = 24 * 60 * 60
+
+var ErrInvalidDwellTime = fmt.Errorf("dwell_seconds must be between 0 and %d", maxDwellSeconds)
+
+// InteractionUseCase は記事の閲覧といいねを記録します
+type InteractionUseCase struct {
+	interactionRepository repository.IInteractionRepository
+	articleRepository     repository.IArticleRepository
+}
+
+func NewInteractionUseCase(interactionRepository repository.IInteractionRepository, articleRepository repository.IArticleRepository) *InteractionUseCase {
+	return &InteractionUseCase{
+		interactionRepository: interactionRepository,
+		articleRepository:     articleRepository,
+	}
+}
+
+// RecordView は記事の閲覧を記録します。dwellSeconds は閲覧した秒数で、不明な場合は nil です
+func (u *InteractionUseCase) RecordView(ctx context.Context, userID string, articleID string, dwellSeconds *int) error {
+	if dwellSeconds != nil && (*dwellSeconds < 0 || *dwellSeconds > maxDwellSeconds) {
+		return ErrInvalidDwellTime
+	}
+	if err := u.ensureArticle(ctx, articleID); err != nil {
+		return err
+	}
+
+	return u.interactionRepository.RecordInteraction(ctx, &model.InteractionEvent{
+		UserID:       userID,
+		ArticleID:    articleID,
+		EventType:    model.InteractionView,
+		DwellSeconds: dwellSeconds,
+		CreatedAt:    time.Now(),
+	})
+}
+
+// SetLike は記事のいいねを設定または解除します。既に同じ状態の場合は何も記録しません
+func (u *InteractionUseCase) SetLike(ctx context.Context, userID string, articleID string, liked bool) error {
+	if err := u.ensureArticle(ctx, articleID); err != nil {
+		return err
+	}
+
+	return u.interactionRepository.SetLike(ctx, userID, articleID, liked)
+}
+
+func (u *InteractionUseCase) ensureArticle(ctx context.Context, articleID string) error {
+	_, err := u.articleRepository.GetArticleByID(ctx, articleID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrArticleNotFound
+	}
+	return err
+}
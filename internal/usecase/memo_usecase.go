@@ -2,11 +2,16 @@ package usecase
 
 import (
 	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"SmartBook/internal/search"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// memoSnippetLength はメモ検索の結果のスニペットの文字数です
+const memoSnippetLength = 120
+
 type MemoUseCase struct {
 	db *gorm.DB
 }
@@ -37,11 +42,16 @@ func (u *MemoUseCase) CreateMemo(memoCreateReq *model.MemoData, articleCreateReq
 	result := tx.Where("id = ?", articleCreateReq.ID).First(&model.ArticleData{})
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
+			articleCreateReq.CanonicalURL = repository.ArticleCanonicalKey(articleCreateReq.ID, articleCreateReq.URL)
 			result = tx.Create(articleCreateReq)
 			if result.Error != nil {
 				tx.Rollback()
 				return result.Error
 			}
+			if err := repository.RefreshArticleSearchVectors(tx, []string{articleCreateReq.ID}); err != nil {
+				tx.Rollback()
+				return err
+			}
 		} else {
 			tx.Rollback()
 			return result.Error
@@ -54,6 +64,11 @@ func (u *MemoUseCase) CreateMemo(memoCreateReq *model.MemoData, articleCreateReq
 		return result.Error
 	}
 
+	if err := repository.RefreshMemoSearchVectors(tx, []int{memoCreateReq.ID}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
@@ -72,7 +87,14 @@ func (u *MemoUseCase) UpdateMemo(req *model.MemoRequest) error {
 		return result.Error
 	}
 
-	return nil
+	// 検索用ベクトルを更新後の本文で作り直す
+	var ids []int
+	result = u.db.Model(&model.MemoData{}).Where("user_id = ? AND article_id = ?", req.UserID, req.ArticleID).Pluck("id", &ids)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return repository.RefreshMemoSearchVectors(u.db, ids)
 }
 
 func (u *MemoUseCase) GetMemo(req *model.MemoRequest) (*model.MemoData, error) {
@@ -93,3 +115,51 @@ func (u *MemoUseCase) DeleteMemo(req *model.MemoRequest) error {
 
 	return nil
 }
+
+// SearchMemos はユーザーのメモを本文とメモした記事のタイトルから全文検索し、関連度の高い順に返します
+// 本文の一致はタイトルの一致より関連度が高くなります
+func (u *MemoUseCase) SearchMemos(userID string, query string, limit int) ([]model.MemoSearchResult, error) {
+	tsquery := search.TermQuery(query, "")
+	if tsquery == "" {
+		return []model.MemoSearchResult{}, nil
+	}
+
+	var rows []struct {
+		ID           int
+		ArticleID    string
+		ArticleTitle string
+		Content      string
+		CreatedAt    time.Time
+		UpdatedAt    time.Time
+	}
+	result := u.db.Table("memo_data").
+		Select("memo_data.id, memo_data.article_id, coalesce(article_data.title, '') AS article_title, memo_data.content, memo_data.created_at, memo_data.updated_at").
+		Joins("LEFT JOIN article_data ON article_data.id = memo_data.article_id").
+		Where("memo_data.user_id = ? AND memo_data.search_vector @@ CAST(? AS tsquery)", userID, tsquery).
+		Order(gorm.Expr("ts_rank_cd(memo_data.search_vector, CAST(? AS tsquery)) DESC", tsquery)).
+		Order("memo_data.updated_at DESC").
+		Limit(limit).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	terms := search.HighlightTermsOf(query)
+	results := make([]model.MemoSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, model.MemoSearchResult{
+			ID:           row.ID,
+			ArticleID:    row.ArticleID,
+			ArticleTitle: row.ArticleTitle,
+			Content:      row.Content,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+			Highlights: model.MemoSearchHighlights{
+				Content:      search.Highlight(row.Content, terms, memoSnippetLength),
+				ArticleTitle: search.Highlight(row.ArticleTitle, terms, 0),
+			},
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// maxRedirects は本文抽出で追いかけるリダイレクトの最大回数です
+const maxRedirects = 10
+
+// ErrForbiddenURL はサーバーから取得してはいけない URL (http/https 以外やプライベートアドレス) の場合のエラーです
+var ErrForbiddenURL = errors.New("url is not allowed")
+
+// sharedAddressSpace は CGNAT で使われる 100.64.0.0/10 で、IsPrivate に含まれないため個別に拒否します
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newPublicHTTPClient はインターネット上の公開されたアドレスにのみ接続する HTTP クライアントを作成します
+// ユーザーが指定できる URL をサーバーから取得する場合に、内部のサービスやメタデータ API へのアクセス (SSRF) を防ぎます
+// 接続先の IP アドレスは名前解決の後に確認するため、DNS で内部のアドレスを返す場合も拒否します
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkPublicAddress(address)
+		},
+	}
+	transport := &http.Transport{
+		// プロキシを経由すると接続先の確認ができないため使わない
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkPublicURL(req.URL)
+		},
+	}
+}
+
+// checkPublicURL は URL のスキームが http/https であることを確認します
+func checkPublicURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrForbiddenURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: missing host", ErrForbiddenURL)
+	}
+	return nil
+}
+
+// checkPublicAddress は接続先の "IP:ポート" がループバック・プライベート・リンクローカルなどのアドレスでないことを確認します
+func checkPublicAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenURL, address)
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenURL, address)
+	}
+	ip = ip.Unmap()
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s is not a public address", ErrForbiddenURL, ip)
+	}
+	return nil
+}
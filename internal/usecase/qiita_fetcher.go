@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	qiitaBaseURL = "https://qiita.com/api/v2"
+	// qiitaMaxPerPage は Qiita API v2 で1ページに取得できる最大件数です
+	qiitaMaxPerPage = 100
+)
+
+type QiitaFetcher struct {
+	client      *http.Client
+	baseURL     string
+	accessToken string
+	policy      retryPolicy
+}
+
+// NewQiitaFetcher は Qiita API v2 から記事を取得する ArticleFetcher を作成します
+// QIITA_ACCESS_TOKEN が設定されている場合は認証付きでリクエストし、レート制限を緩和します
+func NewQiitaFetcher(client *http.Client) *QiitaFetcher {
+	return &QiitaFetcher{
+		client:      client,
+		baseURL:     qiitaBaseURL,
+		accessToken: os.Getenv("QIITA_ACCESS_TOKEN"),
+		policy:      defaultRetryPolicy,
+	}
+}
+
+func (f *QiitaFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Article, error) {
+	perPage := limit
+	if perPage > qiitaMaxPerPage {
+		perPage = qiitaMaxPerPage
+	}
+	url := fmt.Sprintf("%s/items?page=1&per_page=%d", f.baseURL, perPage)
+
+	header := http.Header{}
+	if f.accessToken != "" {
+		header.Set("Authorization", "Bearer "+f.accessToken)
+	}
+
+	var qiitaItems []struct {
+		ID         string `json:"id"`
+		Title      string `json:"title"`
+		URL        string `json:"url"`
+		LikesCount int    `json:"likes_count"`
+		CreatedAt  string `json:"created_at"`
+		User       struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"user"`
+		Tags []struct {
+			Name string `json:"name"`
+		} `json:"tags"`
+	}
+	if err := getJSONWithRetry(ctx, f.client, url, header, f.policy, &qiitaItems); err != nil {
+		return nil, fmt.Errorf("failed to fetch items: %w", err)
+	}
+
+	articles := make([]model.Article, 0, len(qiitaItems))
+	for _, item := range qiitaItems {
+		createdAt, _ := time.Parse(time.RFC3339, item.CreatedAt)
+
+		// 表示名が未設定のユーザーも多いため、その場合はユーザーIDを著者とする
+		author := item.User.Name
+		if author == "" {
+			author = item.User.ID
+		}
+
+		tags := make([]string, 0, len(item.Tags))
+		for _, tag := range item.Tags {
+			tags = append(tags, tag.Name)
+		}
+
+		articles = append(articles, model.Article{
+			ID:        fmt.Sprintf("qiita_%s", item.ID),
+			Title:     item.Title,
+			URL:       item.URL,
+			Score:     item.LikesCount,
+			Author:    author,
+			CreatedAt: createdAt,
+			Source:    "Qiita",
+			Tags:      tags,
+		})
+	}
+
+	return articles, nil
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newQiitaTestServer は testdata/qiita_items.json を返すテスト用の Qiita API です
+// per_page を超える件数は返しません
+func newQiitaTestServer(t *testing.T, gotQuery *string, gotAuth *string) *httptest.Server {
+	t.Helper()
+
+	fixture, err := os.ReadFile("testdata/qiita_items.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/items" {
+			http.NotFound(w, r)
+			return
+		}
+		*gotQuery = r.URL.RawQuery
+		*gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+}
+
+func newTestQiitaFetcher(baseURL string) *QiitaFetcher {
+	return &QiitaFetcher{
+		client:  http.DefaultClient,
+		baseURL: baseURL,
+		policy:  retryPolicy{attemptTimeout: time.Second},
+	}
+}
+
+func TestQiitaFetcherFetchArticles(t *testing.T) {
+	var query, auth string
+	server := newQiitaTestServer(t, &query, &auth)
+	defer server.Close()
+
+	articles, err := newTestQiitaFetcher(server.URL).FetchArticles(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("FetchArticles returned error: %v", err)
+	}
+	if query != "page=1&per_page=30" {
+		t.Errorf("query = %q, want page=1&per_page=30", query)
+	}
+	if auth != "" {
+		t.Errorf("Authorization = %q, want empty without an access token", auth)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+
+	first := articles[0]
+	if first.ID != "qiita_c686397e4a0f4f11683d" {
+		t.Errorf("ID = %q, want qiita_ prefix with the item ID", first.ID)
+	}
+	if first.Title != "Go の context を理解する" || first.URL != "https://qiita.com/taro/items/c686397e4a0f4f11683d" {
+		t.Errorf("unexpected title/url: %q %q", first.Title, first.URL)
+	}
+	if first.Score != 42 {
+		t.Errorf("Score = %d, want likes_count 42", first.Score)
+	}
+	if first.Author != "Taro Yamada" {
+		t.Errorf("Author = %q, want the display name", first.Author)
+	}
+	if first.Source != "Qiita" {
+		t.Errorf("Source = %q, want Qiita", first.Source)
+	}
+	if want := []string{"Go", "context"}; !reflect.DeepEqual([]string(first.Tags), want) {
+		t.Errorf("Tags = %v, want %v", first.Tags, want)
+	}
+	if want := time.Date(2024, 5, 1, 0, 30, 0, 0, time.UTC); !first.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %s, want %s", first.CreatedAt, want)
+	}
+
+	// 表示名が未設定の場合はユーザーIDを著者とする
+	if articles[1].Author != "hanako" {
+		t.Errorf("Author = %q, want the user ID as fallback", articles[1].Author)
+	}
+	if articles[2].Score != 0 || len(articles[2].Tags) != 0 {
+		t.Errorf("unexpected score/tags for an item without likes and tags: %d %v", articles[2].Score, articles[2].Tags)
+	}
+}
+
+func TestQiitaFetcherLimit(t *testing.T) {
+	var query, auth string
+	server := newQiitaTestServer(t, &query, &auth)
+	defer server.Close()
+
+	fetcher := newTestQiitaFetcher(server.URL)
+	fetcher.accessToken = "secret"
+
+	if _, err := fetcher.FetchArticles(context.Background(), 500); err != nil {
+		t.Fatalf("FetchArticles returned error: %v", err)
+	}
+	// Qiita API v2 の1ページの上限に切り詰める
+	if query != "page=1&per_page=100" {
+		t.Errorf("query = %q, want per_page capped at 100", query)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", auth)
+	}
+}
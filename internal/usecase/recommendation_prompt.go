@@ -0,0 +1,401 @@
+package usecase
+
+import (
+	"SmartBook/internal/llm"
+	"SmartBook/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPromptTokenBudget は推薦のプロンプト1回あたりのトークン数 (応答を含む) の既定値です
+	// LLM_PROMPT_TOKEN_BUDGET で変更できます。コンテキストの小さいローカルモデルでは小さくします
+	defaultPromptTokenBudget = 3000
+	// minPromptTokenBudget は LLM_PROMPT_TOKEN_BUDGET に指定できる最小値です
+	minPromptTokenBudget = 500
+	// aiCandidateLimit は従来のスコアで絞り込んだ後、LLM に渡す候補の最大件数です
+	aiCandidateLimit = 120
+	// maxRankingRounds はチャンクごとの並べ替えと統合を繰り返す最大回数です
+	maxRankingRounds = 3
+	// maxRankingChunks は1回に並べ替えるチャンクの最大数です
+	// LLM に渡す候補はこの数のチャンクに収まる件数までにします (rankableCandidates)
+	maxRankingChunks = 4
+	// llmRankingTimeout は LLM による並べ替え全体の時間の上限です。サーバーの WriteTimeout (30秒) より短くします
+	llmRankingTimeout = 20 * time.Second
+	// maxPromptHistory はプロンプトに含める閲覧履歴・いいねの最大件数です
+	maxPromptHistory = 20
+	// maxPromptTitleRunes と maxPromptTags はプロンプトに含める記事1件あたりのタイトルの文字数とタグの数です
+	maxPromptTitleRunes = 150
+	maxPromptTags       = 5
+	// responseTokensPerArticle と responseTokenOverhead は応答に必要なトークン数の見積もりに使います
+	// 1件あたり ID と理由 (1文) と確信度を含みます
+	responseTokensPerArticle = 60
+	responseTokenOverhead    = 16
+	// maxReasonRunes は推薦の理由として受け付ける最大文字数です。超えた分は切り詰めます
+	maxReasonRunes = 300
+)
+
+// errPromptBudgetTooSmall はプロンプトの予算に候補が1件も収まらない場合のエラーです
+// 呼び出し元は従来のスコアの順にフォールバックします
+var errPromptBudgetTooSmall = errors.New("prompt token budget is too small for any candidate")
+
+// rankedArticle は推薦した記事です
+// LLM が選んだ場合は理由と確信度 (0〜1) を、TF-IDF で選んだ場合はユーザーのプロフィールと共通の語を、
+// 協調フィルタリングで選んだ場合は最も似ている、ユーザーが保存した記事の ID を持ちます
+type rankedArticle struct {
+	Article    model.Article
+	Reason     string
+	Confidence float64
+	Terms      []string
+	SavedWith  string
+}
+
+// aiRecommendationResponse は LLM に返させる推薦の JSON です
+type aiRecommendationResponse struct {
+	Recommendations []struct {
+		ID         string  `json:"id"`
+		Reason     string  `json:"reason"`
+		Confidence float64 `json:"confidence"`
+	} `json:"recommendations"`
+}
+
+// recommendationSchema は aiRecommendationResponse の JSON Schema です
+var recommendationSchema = &llm.Schema{
+	Type: "object",
+	Properties: map[string]*llm.Schema{
+		"recommendations": {
+			Type:        "array",
+			Description: "Recommended articles, most recommended first",
+			Items: &llm.Schema{
+				Type: "object",
+				Properties: map[string]*llm.Schema{
+					"id":         {Type: "string", Description: "ID of an article from the list"},
+					"reason":     {Type: "string", Description: "One short sentence on why this user would like the article"},
+					"confidence": {Type: "number", Description: "Confidence from 0 to 1"},
+				},
+				Required: []string{"id", "reason", "confidence"},
+			},
+		},
+	},
+	Required: []string{"recommendations"},
+}
+
+// rankWithLLM は候補をプロンプトの予算に収まるチャンクに分けて LLM で並べ替え、結果を統合します
+// チャンクは並行して並べ替え、各チャンクから上位を選んで候補を絞り込みます
+// 絞り込んだ候補が1回のプロンプトに収まった時点で、最後にまとめて並べ替えます
+func (u *ArticleUseCase) rankWithLLM(ctx context.Context, userBehavior string, candidates []model.Article, limit int) ([]rankedArticle, error) {
+	// リクエストの書き込みのタイムアウトまでに応答できるよう、全体の時間を制限する
+	ctx, cancel := context.WithTimeout(ctx, llmRankingTimeout)
+	defer cancel()
+
+	// merged は前の段階までに統合した結果で、以降の並べ替えに失敗した場合に使う
+	var merged []rankedArticle
+	for round := 1; ; round++ {
+		chunks := chunkCandidates(userBehavior, candidates, limit, u.promptTokenBudget)
+		if len(chunks) == 0 {
+			if merged != nil {
+				return merged, nil
+			}
+			return nil, errPromptBudgetTooSmall
+		}
+
+		if len(chunks) == 1 {
+			ranked, err := u.rankChunk(ctx, userBehavior, chunks[0], min(limit, len(chunks[0])))
+			if err != nil && merged != nil {
+				fmt.Println("🟡 AI recommendation for the merged candidates failed, using the merged order:", err)
+				return merged, nil
+			}
+			return ranked, err
+		}
+
+		results, err := u.rankChunks(ctx, userBehavior, chunks, limit)
+		if err != nil {
+			if merged != nil {
+				fmt.Println("🟡 AI recommendation for the merged candidates failed, using the merged order:", err)
+				return merged, nil
+			}
+			return nil, err
+		}
+		merged = interleaveArticles(results)
+
+		// 絞り込めなくなった場合や上限に達した場合は、統合した順のまま返す
+		if len(merged) >= len(candidates) || round >= maxRankingRounds {
+			return merged, nil
+		}
+		candidates = make([]model.Article, 0, len(merged))
+		for _, ranked := range merged {
+			candidates = append(candidates, ranked.Article)
+		}
+	}
+}
+
+// rankChunks はチャンクを並行して並べ替え、チャンクの順に結果を返します
+// 候補を絞り込むため、各チャンクからはチャンクの半分 (最大 limit 件) を選ばせます
+// 失敗したチャンクは従来のスコアの順で上位を使い、すべて失敗した場合のみエラーを返します
+func (u *ArticleUseCase) rankChunks(ctx context.Context, userBehavior string, chunks [][]model.Article, limit int) ([][]rankedArticle, error) {
+	results := make([][]rankedArticle, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []model.Article) {
+			defer wg.Done()
+			n := min(limit, (len(chunk)+1)/2)
+			ranked, err := u.rankChunk(ctx, userBehavior, chunk, n)
+			if err != nil {
+				// チャンクは従来のスコアの順に並んでいるため、失敗したチャンクはその順で上位を使う
+				fmt.Println("🟡 AI recommendation for a chunk failed:", err)
+				errs[i] = err
+				ranked = unrankedArticles(chunk[:n])
+			}
+			results[i] = ranked
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err == nil {
+			return results, nil
+		}
+	}
+	return nil, errs[len(errs)-1]
+}
+
+// rankChunk は候補のうち上位 n 件を LLM に選ばせ、LLM が並べた順に返します
+func (u *ArticleUseCase) rankChunk(ctx context.Context, userBehavior string, articles []model.Article, n int) ([]rankedArticle, error) {
+	prompt := buildRankingPrompt(userBehavior, formatArticlesForAI(articles), n)
+	response, err := u.generate(ctx, llm.Request{
+		Prompt:         prompt,
+		MaxTokens:      responseTokenReserve(n),
+		ResponseSchema: recommendationSchema,
+	}, len(articles))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate AI recommendations with %s: %w", u.llmProvider.Name(), err)
+	}
+
+	ranked, err := parseAIRecommendations(response.Text, articles)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked, nil
+}
+
+// parseAIRecommendations は LLM の応答の JSON を読み取り、候補に含まれる記事のみを LLM が並べた順に返します
+// 候補にない ID (LLM が作り出した ID など) と重複した ID は捨てます
+func parseAIRecommendations(text string, candidates []model.Article) ([]rankedArticle, error) {
+	var response aiRecommendationResponse
+	if err := json.Unmarshal([]byte(trimCodeFence(text)), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse AI recommendations: %w", err)
+	}
+
+	byID := make(map[string]model.Article, len(candidates))
+	for _, article := range candidates {
+		byID[article.ID] = article
+	}
+
+	ranked := make([]rankedArticle, 0, len(response.Recommendations))
+	seen := make(map[string]bool, len(response.Recommendations))
+	invalid := 0
+	for _, recommendation := range response.Recommendations {
+		id := strings.TrimSpace(recommendation.ID)
+		article, ok := byID[id]
+		if !ok {
+			invalid++
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		reason := strings.TrimSpace(recommendation.Reason)
+		if runes := []rune(reason); len(runes) > maxReasonRunes {
+			reason = string(runes[:maxReasonRunes]) + "…"
+		}
+		ranked = append(ranked, rankedArticle{
+			Article:    article,
+			Reason:     reason,
+			Confidence: math.Max(0, math.Min(1, recommendation.Confidence)),
+		})
+	}
+	if invalid > 0 {
+		fmt.Printf("🟡 AI recommendation returned %d unknown article IDs\n", invalid)
+	}
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("AI recommendations contain no valid article IDs")
+	}
+	return ranked, nil
+}
+
+// trimCodeFence は応答が ```json ... ``` で囲まれている場合に中身を取り出します
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// backfillRecommendations は推薦が limit 件に満たない場合に、従来のスコアの順 (scored) で残りを補います
+// excluded に含まれる記事 (推薦の方法が候補から除いた記事) は補いません
+func backfillRecommendations(ranked []rankedArticle, scored []model.Article, excluded map[string]bool, limit int) []rankedArticle {
+	if len(ranked) >= limit {
+		return ranked[:limit]
+	}
+
+	seen := make(map[string]bool, len(ranked))
+	for _, recommendation := range ranked {
+		seen[recommendation.Article.ID] = true
+	}
+	for _, article := range scored {
+		if len(ranked) >= limit {
+			break
+		}
+		if !seen[article.ID] && !excluded[article.ID] {
+			seen[article.ID] = true
+			ranked = append(ranked, rankedArticle{Article: article})
+		}
+	}
+	return ranked
+}
+
+// unrankedArticles は LLM を使わずに選んだ記事を、理由のない推薦として扱います
+func unrankedArticles(articles []model.Article) []rankedArticle {
+	ranked := make([]rankedArticle, 0, len(articles))
+	for _, article := range articles {
+		ranked = append(ranked, rankedArticle{Article: article})
+	}
+	return ranked
+}
+
+// generate は LLM を呼び出し、プロンプトと応答の大きさをログに記録します
+func (u *ArticleUseCase) generate(ctx context.Context, req llm.Request, candidates int) (llm.Response, error) {
+	start := time.Now()
+	response, err := u.llmProvider.Generate(ctx, req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Printf("🔴 LLM %s: %d candidates, prompt %d bytes (~%d tokens), failed after %s: %s\n",
+			u.llmProvider.Name(), candidates, len(req.Prompt), llm.EstimateTokens(req.Prompt), elapsed, err)
+		return llm.Response{}, err
+	}
+
+	fmt.Printf("🟢 LLM %s (%s): %d candidates, prompt %d bytes (~%d tokens), response %d bytes (~%d tokens) in %s\n",
+		u.llmProvider.Name(), response.Model, candidates, len(req.Prompt), llm.EstimateTokens(req.Prompt),
+		len(response.Text), llm.EstimateTokens(response.Text), elapsed)
+	return response, nil
+}
+
+func buildRankingPrompt(userBehavior string, articles string, limit int) string {
+	return fmt.Sprintf(`Given the following user behavior: %s
+And the following list of articles:
+%s
+Recommend the top %d articles for this user, most recommended first. Only use IDs from the list.
+Return JSON with a short reason and a confidence from 0 to 1 for each article.
+Example output format: {"recommendations": [{"id": "article_id_1", "reason": "...", "confidence": 0.9}, ...]}`, userBehavior, articles, limit)
+}
+
+// chunkCandidates は候補を、プロンプトと応答の見積もりが budget に収まるチャンクに順に分けます
+// 応答のトークン数はチャンクごとに、そのチャンクから選ばせる最大件数 (limit とチャンクの件数の小さい方) で見積もります
+// 予算が小さすぎて1件の候補も収まらない場合は nil を返します
+func chunkCandidates(userBehavior string, candidates []model.Article, limit int, budget int) [][]model.Article {
+	base := llm.EstimateTokens(buildRankingPrompt(userBehavior, "", limit))
+
+	var chunks [][]model.Article
+	var chunk []model.Article
+	used := base
+	for _, article := range candidates {
+		tokens := llm.EstimateTokens(formatArticleForAI(article))
+		if used+tokens+responseTokenReserve(min(limit, len(chunk)+1)) > budget {
+			if len(chunk) == 0 {
+				return nil
+			}
+			chunks = append(chunks, chunk)
+			chunk = nil
+			used = base
+			if used+tokens+responseTokenReserve(1) > budget {
+				return nil
+			}
+		}
+		chunk = append(chunk, article)
+		used += tokens
+	}
+	if len(chunk) > 0 || len(chunks) == 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// rankableCandidates は候補のうち、maxRankingChunks 個のチャンクに収まる上位の候補を返します
+// LLM に渡す候補の件数は aiCandidateLimit とプロンプトの予算の両方で決まります
+func rankableCandidates(userBehavior string, candidates []model.Article, limit int, budget int) []model.Article {
+	chunks := chunkCandidates(userBehavior, candidates, limit, budget)
+	if len(chunks) <= maxRankingChunks {
+		return candidates
+	}
+
+	n := 0
+	for _, chunk := range chunks[:maxRankingChunks] {
+		n += len(chunk)
+	}
+	fmt.Printf("🟡 LLM_PROMPT_TOKEN_BUDGET=%d fits %d of %d candidates in %d chunks; ranking the top %d by score\n",
+		budget, n, len(candidates), maxRankingChunks, n)
+	return candidates[:n]
+}
+
+// responseTokenReserve は n 件の ID を返す応答に必要なトークン数の見積もりです
+func responseTokenReserve(n int) int {
+	return n*responseTokensPerArticle + responseTokenOverhead
+}
+
+// interleaveArticles は各チャンクの結果を1位から順に交互に並べて統合します
+func interleaveArticles(results [][]rankedArticle) []rankedArticle {
+	var merged []rankedArticle
+	seen := make(map[string]bool)
+	for i := 0; ; i++ {
+		added := false
+		for _, result := range results {
+			if i >= len(result) {
+				continue
+			}
+			added = true
+			if !seen[result[i].Article.ID] {
+				seen[result[i].Article.ID] = true
+				merged = append(merged, result[i])
+			}
+		}
+		if !added {
+			return merged
+		}
+	}
+}
+
+// promptTokenBudgetFromEnv は LLM_PROMPT_TOKEN_BUDGET からプロンプトの予算を読み込みます
+func promptTokenBudgetFromEnv() int {
+	value := os.Getenv("LLM_PROMPT_TOKEN_BUDGET")
+	if value == "" {
+		return defaultPromptTokenBudget
+	}
+
+	budget, err := strconv.Atoi(value)
+	if err != nil || budget < minPromptTokenBudget {
+		fmt.Printf("🟡 invalid LLM_PROMPT_TOKEN_BUDGET=%q, using %d\n", value, defaultPromptTokenBudget)
+		return defaultPromptTokenBudget
+	}
+	return budget
+}
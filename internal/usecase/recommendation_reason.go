@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// trendingPercentile は候補のうちスコアが上位何割の記事を急上昇とみなすかです
+const trendingPercentile = 0.2
+
+// recommendationExplainer はおすすめ記事に付ける推薦の理由を作成します
+type recommendationExplainer struct {
+	interests []string
+	liked     []model.Article
+	// userTags は興味といいねした記事のタグです (小文字)
+	userTags      map[string]bool
+	trendingScore int
+	byID          map[string]model.Article
+}
+
+// newRecommendationExplainer はユーザーの興味・いいねした記事と、候補のスコアの分布から理由の作成に使う情報を準備します
+func newRecommendationExplainer(user *model.User, allArticles []model.Article, candidates []model.Article) *recommendationExplainer {
+	e := &recommendationExplainer{userTags: make(map[string]bool), byID: make(map[string]model.Article, len(allArticles))}
+	for _, interest := range user.Interests {
+		interest = strings.ToLower(interest)
+		e.interests = append(e.interests, interest)
+		e.userTags[interest] = true
+	}
+
+	for _, article := range allArticles {
+		e.byID[article.ID] = article
+	}
+	for _, id := range user.Likes {
+		if article, ok := e.byID[id]; ok {
+			e.liked = append(e.liked, article)
+			for _, tag := range article.Tags {
+				e.userTags[strings.ToLower(tag)] = true
+			}
+		}
+	}
+
+	if len(candidates) > 0 {
+		scores := make([]int, 0, len(candidates))
+		for _, article := range candidates {
+			scores = append(scores, article.Score)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+		e.trendingScore = max(1, scores[int(float64(len(scores)-1)*trendingPercentile)])
+	}
+
+	return e
+}
+
+// explain は記事を推薦した理由を、興味との一致 > いいねした記事との類似 > タグでの急上昇 > 人気 の順に判定します
+func (e *recommendationExplainer) explain(article model.Article) model.RecommendationReason {
+	title := strings.ToLower(article.Title)
+	for _, interest := range e.interests {
+		if strings.Contains(title, interest) || hasTag(article, interest) {
+			return model.RecommendationReason{
+				Type:     model.ReasonInterest,
+				Message:  fmt.Sprintf("Matches your interest %q", interest),
+				Interest: interest,
+			}
+		}
+	}
+
+	if liked, ok := e.mostSimilarLiked(article); ok {
+		return model.RecommendationReason{
+			Type:         model.ReasonSimilarToLiked,
+			Message:      fmt.Sprintf("Similar to %q, which you liked", liked.Title),
+			ArticleID:    liked.ID,
+			ArticleTitle: liked.Title,
+		}
+	}
+
+	if article.Score >= e.trendingScore {
+		for _, tag := range article.Tags {
+			if tag = strings.ToLower(tag); e.userTags[tag] {
+				return model.RecommendationReason{
+					Type:    model.ReasonTrending,
+					Message: fmt.Sprintf("Trending in #%s", tag),
+					Tag:     tag,
+				}
+			}
+		}
+	}
+
+	return model.RecommendationReason{
+		Type:    model.ReasonPopular,
+		Message: fmt.Sprintf("Popular on %s", article.Source),
+	}
+}
+
+// explainRanked は LLM が理由を返した記事にはその理由を、協調フィルタリングで選んだ記事には一緒に保存された記事を、
+// それ以外の記事には explain の理由を付けます
+// explain で人気以外の理由が見つからず、TF-IDF で共通の語がある場合はその語を理由にします
+func (e *recommendationExplainer) explainRanked(ranked []rankedArticle) []model.RecommendedArticle {
+	recommended := make([]model.RecommendedArticle, 0, len(ranked))
+	for _, r := range ranked {
+		if r.SavedWith != "" {
+			recommended = append(recommended, model.RecommendedArticle{Article: r.Article, Reason: e.alsoSavedReason(r.SavedWith)})
+			continue
+		}
+
+		reason := e.explain(r.Article)
+		if reason.Type == model.ReasonPopular && len(r.Terms) > 0 {
+			reason = model.RecommendationReason{
+				Type:    model.ReasonContent,
+				Message: "Similar to what you read: " + strings.Join(r.Terms, ", "),
+				Terms:   r.Terms,
+			}
+		}
+		if r.Reason != "" {
+			confidence := r.Confidence
+			reason = model.RecommendationReason{
+				Type:       model.ReasonAI,
+				Message:    r.Reason,
+				Confidence: &confidence,
+			}
+		}
+		recommended = append(recommended, model.RecommendedArticle{Article: r.Article, Reason: reason})
+	}
+	return recommended
+}
+
+// alsoSavedReason は、ユーザーが保存した記事を保存した他のユーザーが、推薦した記事も保存していることを理由にします
+func (e *recommendationExplainer) alsoSavedReason(savedID string) model.RecommendationReason {
+	reason := model.RecommendationReason{
+		Type:      model.ReasonAlsoSaved,
+		Message:   "People who saved an article you saved also saved this",
+		ArticleID: savedID,
+	}
+	if saved, ok := e.byID[savedID]; ok {
+		reason.Message = fmt.Sprintf("People who saved %q also saved this", saved.Title)
+		reason.ArticleTitle = saved.Title
+	}
+	return reason
+}
+
+// mostSimilarLiked は記事と共通するタグが最も多い、いいねした記事を返します
+func (e *recommendationExplainer) mostSimilarLiked(article model.Article) (model.Article, bool) {
+	var best model.Article
+	bestShared := 0
+	for _, liked := range e.liked {
+		if liked.ID == article.ID {
+			continue
+		}
+		shared := 0
+		for _, tag := range liked.Tags {
+			if hasTag(article, tag) {
+				shared++
+			}
+		}
+		if shared > bestShared {
+			best, bestShared = liked, shared
+		}
+	}
+	return best, bestShared > 0
+}
+
+func hasTag(article model.Article, tag string) bool {
+	for _, t := range article.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"SmartBook/internal/search"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxSavedSearches はユーザーごとに保存できる検索条件の数です
+	maxSavedSearches = 50
+	// maxSavedSearchMatches は一致した記事の一覧で返す最大件数です
+	maxSavedSearchMatches = 100
+)
+
+var (
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrTooManySavedSearches = fmt.Errorf("saved searches are limited to %d per user", maxSavedSearches)
+	ErrInvalidSavedSearch   = errors.New("name (up to 255 characters) and query are required")
+)
+
+// SavedSearchUseCase はユーザーが保存した検索条件を管理し、取り込んだ新しい記事と照合します
+type SavedSearchUseCase struct {
+	savedSearchRepository repository.ISavedSearchRepository
+	articleRepository     repository.IArticleRepository
+}
+
+func NewSavedSearchUseCase(savedSearchRepository repository.ISavedSearchRepository, articleRepository repository.IArticleRepository) *SavedSearchUseCase {
+	return &SavedSearchUseCase{
+		savedSearchRepository: savedSearchRepository,
+		articleRepository:     articleRepository,
+	}
+}
+
+// GetSavedSearches はユーザーの保存した検索条件を未読件数とともに返します
+func (u *SavedSearchUseCase) GetSavedSearches(ctx context.Context, userID string) ([]model.SavedSearchResponse, error) {
+	savedSearches, err := u.savedSearchRepository.GetSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := u.savedSearchRepository.CountUnreadMatches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.SavedSearchResponse, 0, len(savedSearches))
+	for _, savedSearch := range savedSearches {
+		responses = append(responses, model.SavedSearchResponse{
+			SavedSearch: savedSearch,
+			UnreadCount: unread[savedSearch.ID],
+		})
+	}
+	return responses, nil
+}
+
+func (u *SavedSearchUseCase) GetSavedSearch(ctx context.Context, userID string, id int) (*model.SavedSearchResponse, error) {
+	savedSearch, err := u.getSavedSearch(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := u.savedSearchRepository.CountUnreadMatches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SavedSearchResponse{SavedSearch: savedSearch, UnreadCount: unread[savedSearch.ID]}, nil
+}
+
+// CreateSavedSearch は検索条件を保存します。保存した後に取り込まれた記事から一致を記録します
+// クエリの構文が正しくない場合は *search.ParseError を返します
+func (u *SavedSearchUseCase) CreateSavedSearch(ctx context.Context, userID string, req model.SavedSearchRequest) (*model.SavedSearchResponse, error) {
+	name, query, err := validateSavedSearch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := u.savedSearchRepository.CountSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxSavedSearches {
+		return nil, ErrTooManySavedSearches
+	}
+
+	now := time.Now()
+	savedSearch := model.SavedSearch{
+		UserID:        userID,
+		Name:          name,
+		Query:         query,
+		LastCheckedAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := u.savedSearchRepository.CreateSavedSearch(ctx, &savedSearch); err != nil {
+		return nil, err
+	}
+
+	return &model.SavedSearchResponse{SavedSearch: savedSearch}, nil
+}
+
+// UpdateSavedSearch は検索条件の名前とクエリを更新します
+// クエリを変更した場合は、以前のクエリで一致した記事の記録を削除します
+func (u *SavedSearchUseCase) UpdateSavedSearch(ctx context.Context, userID string, id int, req model.SavedSearchRequest) (*model.SavedSearchResponse, error) {
+	name, query, err := validateSavedSearch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	savedSearch, err := u.getSavedSearch(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	queryChanged := savedSearch.Query != query
+	savedSearch.Name = name
+	savedSearch.Query = query
+	savedSearch.UpdatedAt = now
+	if queryChanged {
+		savedSearch.LastCheckedAt = now
+		savedSearch.LastCheckedArticleID = ""
+	}
+	if err := u.savedSearchRepository.UpdateSavedSearch(ctx, &savedSearch, queryChanged); err != nil {
+		return nil, err
+	}
+
+	return u.GetSavedSearch(ctx, userID, id)
+}
+
+func (u *SavedSearchUseCase) DeleteSavedSearch(ctx context.Context, userID string, id int) error {
+	err := u.savedSearchRepository.DeleteSavedSearch(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSavedSearchNotFound
+	}
+	return err
+}
+
+// GetMatches は検索条件に一致した記事を返します
+// all が false の場合は未読の一致を古く一致した順に最大 maxSavedSearchMatches 件返し、返した一致までを既読にします
+// 残りの未読がある場合は HasMore が true になり、もう一度呼び出すと続きを返します
+func (u *SavedSearchUseCase) GetMatches(ctx context.Context, userID string, id int, all bool) (*model.SavedSearchMatchesResponse, error) {
+	savedSearch, err := u.getSavedSearch(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if all {
+		articles, err := u.savedSearchRepository.GetMatches(ctx, savedSearch.ID, maxSavedSearchMatches)
+		if err != nil {
+			return nil, err
+		}
+		return &model.SavedSearchMatchesResponse{
+			SavedSearch: model.SavedSearchResponse{SavedSearch: savedSearch},
+			Articles:    articles,
+		}, nil
+	}
+
+	articles, err := u.savedSearchRepository.GetUnreadMatches(ctx, savedSearch, maxSavedSearchMatches+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(articles) > maxSavedSearchMatches
+	if hasMore {
+		articles = articles[:maxSavedSearchMatches]
+	}
+
+	// 現在の日時ではなく返した最後の一致までを既読にし、返していない一致や処理中に記録された一致を未読のまま残す
+	if len(articles) > 0 {
+		last := articles[len(articles)-1]
+		if err := u.savedSearchRepository.MarkChecked(ctx, savedSearch.ID, last.MatchedAt, last.ID); err != nil {
+			return nil, err
+		}
+		savedSearch.LastCheckedAt = last.MatchedAt
+		savedSearch.LastCheckedArticleID = last.ID
+	}
+
+	return &model.SavedSearchMatchesResponse{
+		SavedSearch: model.SavedSearchResponse{SavedSearch: savedSearch},
+		Articles:    articles,
+		HasMore:     hasMore,
+	}, nil
+}
+
+// EvaluateNewArticles は取り込んだ新しい記事を全ての保存した検索条件と照合し、一致を記録します
+// IngestionUseCase から新しい記事が保存されるたびに呼び出されます
+func (u *SavedSearchUseCase) EvaluateNewArticles(ctx context.Context, articleIDs []string) {
+	savedSearches, err := u.savedSearchRepository.GetAllSavedSearches(ctx)
+	if err != nil {
+		fmt.Println("🔴 failed to load saved searches:", err)
+		return
+	}
+
+	matchedAt := time.Now()
+	for _, savedSearch := range savedSearches {
+		query, err := search.Parse(savedSearch.Query)
+		if err != nil {
+			// 保存時に検証しているため、ここで失敗するのは構文を変更した場合のみ
+			fmt.Printf("🟡 skipping saved search %d: %s\n", savedSearch.ID, err)
+			continue
+		}
+
+		matched, err := u.articleRepository.MatchArticleIDs(ctx, query, articleIDs)
+		if err != nil {
+			fmt.Printf("🔴 failed to evaluate saved search %d: %s\n", savedSearch.ID, err)
+			continue
+		}
+		if err := u.savedSearchRepository.SaveMatches(ctx, savedSearch.ID, matched, matchedAt); err != nil {
+			fmt.Printf("🔴 failed to save matches for saved search %d: %s\n", savedSearch.ID, err)
+		}
+	}
+}
+
+func (u *SavedSearchUseCase) getSavedSearch(ctx context.Context, userID string, id int) (model.SavedSearch, error) {
+	savedSearch, err := u.savedSearchRepository.GetSavedSearch(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.SavedSearch{}, ErrSavedSearchNotFound
+	}
+	return savedSearch, err
+}
+
+// validateSavedSearch は名前とクエリの空白を取り除き、クエリの構文を検証します
+func validateSavedSearch(req model.SavedSearchRequest) (string, string, error) {
+	name := strings.TrimSpace(req.Name)
+	query := strings.TrimSpace(req.Query)
+	if name == "" || query == "" || len([]rune(name)) > 255 {
+		return "", "", ErrInvalidSavedSearch
+	}
+	if _, err := search.Parse(query); err != nil {
+		return "", "", err
+	}
+	return name, query, nil
+}
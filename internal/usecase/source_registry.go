@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SourceConfig は記事ソースの登録情報です
+type SourceConfig struct {
+	// Key は環境変数で設定を上書きする際の識別子です (例: HACKERNEWS → SOURCE_HACKERNEWS_ENABLED)
+	Key string
+	// Name は表示名で、model.Article.Source と一致します
+	Name         string
+	IDPrefix     string
+	DefaultLimit int
+	Enabled      bool
+	// Weight はおすすめ記事のスコア計算でソースの記事スコアに掛ける重みです
+	Weight   float64
+	Interval time.Duration
+	Fetcher  ArticleFetcher
+}
+
+// SourceRegistry は記事ソースを名前で管理します
+type SourceRegistry struct {
+	mu      sync.RWMutex
+	sources []SourceConfig
+}
+
+func NewSourceRegistry() *SourceRegistry {
+	return &SourceRegistry{}
+}
+
+// NewDefaultSourceRegistry は組み込みのソースと feed_sources テーブルのフィードを登録したレジストリを作成します
+// 各ソースの設定は SOURCE_<KEY>_ENABLED / _LIMIT / _WEIGHT / _INTERVAL 環境変数で上書きできます
+func NewDefaultSourceRegistry(ctx context.Context, client *http.Client, feedRepository repository.IFeedRepository) *SourceRegistry {
+	registry := NewSourceRegistry()
+
+	defaults := []SourceConfig{
+		{Key: "HACKERNEWS", Name: "Hacker News", IDPrefix: "hn_", Fetcher: NewHackerNewsFetcher(client)},
+		{Key: "DEVTO", Name: "DEV.to", IDPrefix: "dev_", Fetcher: &DevToFetcher{client: client}},
+		{Key: "QIITA", Name: "Qiita", IDPrefix: "qiita_", Fetcher: NewQiitaFetcher(client)},
+		{Key: "ZENN", Name: "Zenn", IDPrefix: "zenn_", Fetcher: NewZennFetcher(client)},
+	}
+	for _, config := range defaults {
+		config.DefaultLimit = 100
+		config.Enabled = true
+		config.Weight = 1
+		config.Interval = 5 * time.Minute
+		registry.mustRegister(applySourceEnv(config))
+	}
+
+	feeds, err := feedRepository.GetEnabledFeeds(ctx)
+	if err != nil {
+		fmt.Println("🔴 failed to load feed sources:", err)
+	}
+	for _, feed := range feeds {
+		// フィードは SOURCE_FEED_* で全てのフィードの設定を、SOURCE_FEED_<ID>_* (ID は feed_sources.id) で個別の設定を上書きする
+		// 記事IDはフィードURLとGUIDのハッシュで、全てのフィードで接頭辞 feed_ を共有する
+		config := applySourceEnv(SourceConfig{
+			Key:          "FEED",
+			Name:         feed.Name,
+			IDPrefix:     "feed_",
+			DefaultLimit: 50,
+			Enabled:      true,
+			Weight:       1,
+			Interval:     30 * time.Minute,
+			Fetcher:      NewFeedFetcher(client, feed),
+		})
+		config.Key = fmt.Sprintf("FEED_%d", feed.ID)
+		config = applySourceEnv(config)
+		if err := registry.Register(config); err != nil {
+			fmt.Println("🔴 failed to register feed source:", err)
+		}
+	}
+
+	return registry
+}
+
+// Register はソースを登録します。同じ名前のソースは登録できません
+func (r *SourceRegistry) Register(config SourceConfig) error {
+	if config.Name == "" || config.Fetcher == nil {
+		return fmt.Errorf("source name and fetcher are required")
+	}
+	if config.Interval <= 0 {
+		return fmt.Errorf("source %s: interval must be positive", config.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, source := range r.sources {
+		if source.Name == config.Name {
+			return fmt.Errorf("source %s is already registered", config.Name)
+		}
+	}
+	r.sources = append(r.sources, config)
+	return nil
+}
+
+func (r *SourceRegistry) mustRegister(config SourceConfig) {
+	if err := r.Register(config); err != nil {
+		panic(err)
+	}
+}
+
+// Sources は登録順に全てのソースを返します
+func (r *SourceRegistry) Sources() []SourceConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]SourceConfig{}, r.sources...)
+}
+
+// EnabledSources は有効なソースのみを返します
+func (r *SourceRegistry) EnabledSources() []SourceConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sources := make([]SourceConfig, 0, len(r.sources))
+	for _, source := range r.sources {
+		if source.Enabled {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// Has は指定した名前のソースが登録されているかを返します
+func (r *SourceRegistry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, source := range r.sources {
+		if source.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Weight はソースの重みを返します。未登録のソースは 1 とします
+func (r *SourceRegistry) Weight(name string) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, source := range r.sources {
+		if source.Name == name {
+			return source.Weight
+		}
+	}
+	return 1
+}
+
+// applySourceEnv は SOURCE_<KEY>_* 環境変数でソースの設定を上書きします
+func applySourceEnv(config SourceConfig) SourceConfig {
+	prefix := "SOURCE_" + config.Key + "_"
+
+	if value := os.Getenv(prefix + "ENABLED"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			config.Enabled = enabled
+		} else {
+			fmt.Printf("🟡 invalid bool %sENABLED=%q\n", prefix, value)
+		}
+	}
+	if value := os.Getenv(prefix + "LIMIT"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+			config.DefaultLimit = limit
+		} else {
+			fmt.Printf("🟡 invalid limit %sLIMIT=%q\n", prefix, value)
+		}
+	}
+	if value := os.Getenv(prefix + "WEIGHT"); value != "" {
+		if weight, err := strconv.ParseFloat(value, 64); err == nil && weight >= 0 {
+			config.Weight = weight
+		} else {
+			fmt.Printf("🟡 invalid weight %sWEIGHT=%q\n", prefix, value)
+		}
+	}
+	config.Interval = durationFromEnv(prefix+"INTERVAL", config.Interval)
+
+	return config
+}
+
+// sourceHealth はソースの設定と直近の取り込み状況から状態を判定します
+func sourceHealth(config SourceConfig, status model.SourceStatus) string {
+	switch {
+	case !config.Enabled:
+		return "disabled"
+	case status.LastRunAt.IsZero():
+		return "pending"
+	case status.LastError == "":
+		return "healthy"
+	case status.LastSuccessAt.IsZero():
+		return "down"
+	default:
+		return "degraded"
+	}
+}
+
+func sourceInfo(config SourceConfig, status model.SourceStatus) model.SourceInfo {
+	return model.SourceInfo{
+		Key:          config.Key,
+		Name:         config.Name,
+		IDPrefix:     config.IDPrefix,
+		DefaultLimit: config.DefaultLimit,
+		Enabled:      config.Enabled,
+		Weight:       config.Weight,
+		Interval:     config.Interval.String(),
+		Health:       sourceHealth(config, status),
+		Status:       status,
+	}
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"SmartBook/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxInterests はユーザーが登録できる興味の数です
+	maxInterests = 20
+	// maxInterestLength は興味1つあたりの最大文字数です
+	maxInterestLength = 50
+	// defaultInterestVocabularyLimit は興味の候補として返すタグの件数の既定値です
+	defaultInterestVocabularyLimit = 50
+	// maxInterestVocabularyLimit は興味の候補として返すタグの件数の上限です
+	maxInterestVocabularyLimit = 200
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmailAlreadyExists = errors.New("email is already in use")
+	ErrInvalidName        = errors.New("name must be 1 to 255 characters")
+	ErrInvalidEmail       = errors.New("email is not a valid address")
+	ErrInvalidInterests   = fmt.Errorf("interests must contain 1 to %d items of up to %d characters", maxInterests, maxInterestLength)
+)
+
+type UserUseCase struct {
+	userRepository    repository.IUserRepository
+	articleRepository repository.IArticleRepository
+}
+
+func NewUserUseCase(userRepository repository.IUserRepository, articleRepository repository.IArticleRepository) *UserUseCase {
+	return &UserUseCase{
+		userRepository:    userRepository,
+		articleRepository: articleRepository,
+	}
+}
+
+// GetUserByID はユーザーの興味・いいね・最近閲覧した記事を含むプロフィールを返します
+func (u *UserUseCase) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	user, err := u.userRepository.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetProfile はパスワードを除いたユーザーのプロフィールを返します
+func (u *UserUseCase) GetProfile(ctx context.Context, id string) (*model.UserProfile, error) {
+	user, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := toUserProfile(*user)
+	return &profile, nil
+}
+
+// UpdateProfile は指定された項目のみ検証して更新します
+// 初めて興味を登録した時にオンボーディングを完了とします
+func (u *UserUseCase) UpdateProfile(ctx context.Context, id string, req model.UserProfileUpdate) (*model.UserProfile, error) {
+	user, err := u.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len([]rune(name)) > 255 {
+			return nil, ErrInvalidName
+		}
+		user.Name = name
+	}
+	if req.Email != nil {
+		email, err := normalizeEmail(*req.Email)
+		if err != nil {
+			return nil, err
+		}
+		user.Email = email
+	}
+	if req.Interests != nil {
+		interests, err := normalizeInterests(*req.Interests)
+		if err != nil {
+			return nil, err
+		}
+		user.Interests = model.StringArray(interests)
+		if user.OnboardedAt == nil {
+			user.OnboardedAt = &now
+		}
+	}
+	user.UpdatedAt = now
+
+	if err := u.userRepository.UpdateUser(ctx, user); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrUserNotFound
+		case errors.Is(err, repository.ErrEmailAlreadyExists):
+			return nil, ErrEmailAlreadyExists
+		}
+		return nil, err
+	}
+
+	profile := toUserProfile(*user)
+	return &profile, nil
+}
+
+// GetInterestVocabulary は興味の候補として、記事に付いているタグを記事の件数の多い順に返します
+func (u *UserUseCase) GetInterestVocabulary(ctx context.Context, prefix string, limit int) ([]model.FacetCount, error) {
+	if limit <= 0 {
+		limit = defaultInterestVocabularyLimit
+	}
+	if limit > maxInterestVocabularyLimit {
+		limit = maxInterestVocabularyLimit
+	}
+
+	return u.articleRepository.GetPopularTags(ctx, strings.TrimSpace(prefix), limit)
+}
+
+// normalizeEmail はメールアドレスの形式を検証し、表示名などを除いたアドレスのみを返します
+func normalizeEmail(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || len(value) > 255 {
+		return "", ErrInvalidEmail
+	}
+	return address.Address, nil
+}
+
+// normalizeInterests は興味を小文字にして空白を取り除き、重複を除きます
+// タグの集計と同じく大文字小文字を区別しないため、小文字に揃えます
+func normalizeInterests(values []string) ([]string, error) {
+	interests := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		interest := strings.ToLower(strings.TrimSpace(value))
+		if interest == "" || len([]rune(interest)) > maxInterestLength {
+			return nil, ErrInvalidInterests
+		}
+		if seen[interest] {
+			continue
+		}
+		seen[interest] = true
+		interests = append(interests, interest)
+	}
+	if len(interests) == 0 || len(interests) > maxInterests {
+		return nil, ErrInvalidInterests
+	}
+	return interests, nil
+}
+
+func toUserProfile(user model.User) model.UserProfile {
+	return model.UserProfile{
+		ID:                 user.ID,
+		Name:               user.Name,
+		Email:              user.Email,
+		Interests:          nonNilStrings(user.Interests),
+		RecentViews:        nonNilStrings(user.RecentViews),
+		Likes:              nonNilStrings(user.Likes),
+		OnboardingRequired: user.NeedsOnboarding(),
+		OnboardedAt:        user.OnboardedAt,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+	}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
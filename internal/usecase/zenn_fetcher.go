@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"SmartBook/internal/model"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const zennBaseURL = "https://zenn.dev"
+
+type ZennFetcher struct {
+	client  *http.Client
+	baseURL string
+	policy  retryPolicy
+}
+
+// NewZennFetcher は Zenn のトレンド記事を取得する ArticleFetcher を作成します
+func NewZennFetcher(client *http.Client) *ZennFetcher {
+	return &ZennFetcher{
+		client:  client,
+		baseURL: zennBaseURL,
+		policy:  defaultRetryPolicy,
+	}
+}
+
+func (f *ZennFetcher) FetchArticles(ctx context.Context, limit int) ([]model.Article, error) {
+	// order=daily は Zenn のトレンド順です
+	url := fmt.Sprintf("%s/api/articles?order=daily&count=%d", f.baseURL, limit)
+
+	var zennResponse struct {
+		Articles []struct {
+			ID          int    `json:"id"`
+			Title       string `json:"title"`
+			Path        string `json:"path"`
+			LikedCount  int    `json:"liked_count"`
+			PublishedAt string `json:"published_at"`
+			ArticleType string `json:"article_type"`
+			User        struct {
+				Username string `json:"username"`
+				Name     string `json:"name"`
+			} `json:"user"`
+			// 一覧の API は現在トピックを返さないことが多く、記事ごとに詳細を取得するとリクエストが件数分増えるため、
+			// 返された場合のみタグとして使います
+			Topics []struct {
+				Name string `json:"name"`
+			} `json:"topics"`
+		} `json:"articles"`
+	}
+	if err := getJSONWithRetry(ctx, f.client, url, nil, f.policy, &zennResponse); err != nil {
+		return nil, fmt.Errorf("failed to fetch articles: %w", err)
+	}
+
+	articles := make([]model.Article, 0, len(zennResponse.Articles))
+	for i, a := range zennResponse.Articles {
+		if i >= limit {
+			break
+		}
+
+		publishedTime, _ := time.Parse(time.RFC3339, a.PublishedAt)
+
+		author := a.User.Name
+		if author == "" {
+			author = a.User.Username
+		}
+
+		tags := make([]string, 0, len(a.Topics))
+		for _, topic := range a.Topics {
+			tags = append(tags, topic.Name)
+		}
+
+		articles = append(articles, model.Article{
+			ID:        fmt.Sprintf("zenn_%d", a.ID),
+			Title:     a.Title,
+			URL:       f.baseURL + a.Path,
+			Score:     a.LikedCount,
+			Author:    author,
+			CreatedAt: publishedTime,
+			Source:    "Zenn",
+			Tags:      tags,
+		})
+	}
+
+	return articles, nil
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newZennTestServer は testdata/zenn_articles.json を返すテスト用の Zenn API です
+// Zenn と同様に count より多くの記事を返す場合を確かめるため、count は無視します
+func newZennTestServer(t *testing.T, gotQuery *string) *httptest.Server {
+	t.Helper()
+
+	fixture, err := os.ReadFile("testdata/zenn_articles.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/articles" {
+			http.NotFound(w, r)
+			return
+		}
+		*gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+}
+
+func newTestZennFetcher(baseURL string) *ZennFetcher {
+	return &ZennFetcher{
+		client:  http.DefaultClient,
+		baseURL: baseURL,
+		policy:  retryPolicy{attemptTimeout: time.Second},
+	}
+}
+
+func TestZennFetcherFetchArticles(t *testing.T) {
+	var query string
+	server := newZennTestServer(t, &query)
+	defer server.Close()
+
+	articles, err := newTestZennFetcher(server.URL).FetchArticles(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("FetchArticles returned error: %v", err)
+	}
+	if query != "order=daily&count=30" {
+		t.Errorf("query = %q, want order=daily&count=30", query)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+
+	first := articles[0]
+	if first.ID != "zenn_310001" {
+		t.Errorf("ID = %q, want zenn_ prefix with the article ID", first.ID)
+	}
+	if first.URL != server.URL+"/alice/articles/rust-cli-tool" {
+		t.Errorf("URL = %q, want base URL joined with the path", first.URL)
+	}
+	if first.Score != 120 {
+		t.Errorf("Score = %d, want liked_count 120", first.Score)
+	}
+	if first.Author != "Alice" {
+		t.Errorf("Author = %q, want the display name", first.Author)
+	}
+	if first.Source != "Zenn" {
+		t.Errorf("Source = %q, want Zenn", first.Source)
+	}
+	if want := []string{"rust", "cli"}; !reflect.DeepEqual([]string(first.Tags), want) {
+		t.Errorf("Tags = %v, want topic names %v", first.Tags, want)
+	}
+	if want := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC); !first.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %s, want %s", first.CreatedAt, want)
+	}
+
+	// 表示名が未設定の場合はユーザー名を著者とし、トピックがなければタグは空にする
+	if articles[1].Author != "bob" {
+		t.Errorf("Author = %q, want the username as fallback", articles[1].Author)
+	}
+	if len(articles[1].Tags) != 0 {
+		t.Errorf("Tags = %v, want empty without topics", articles[1].Tags)
+	}
+}
+
+func TestZennFetcherLimit(t *testing.T) {
+	var query string
+	server := newZennTestServer(t, &query)
+	defer server.Close()
+
+	articles, err := newTestZennFetcher(server.URL).FetchArticles(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("FetchArticles returned error: %v", err)
+	}
+	if query != "order=daily&count=2" {
+		t.Errorf("query = %q, want count=2", query)
+	}
+	// API が count より多く返しても limit 件に切り詰める
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(articles))
+	}
+	if articles[0].ID != "zenn_310001" || articles[1].ID != "zenn_310002" {
+		t.Errorf("unexpected articles: %s, %s", articles[0].ID, articles[1].ID)
+	}
+}